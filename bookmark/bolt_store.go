@@ -0,0 +1,141 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var bookmarksBucket = []byte("bookmarks")
+
+// BoltStore stores each bookmark as a key in a bbolt db, Update only writes
+// the changed bookmarks.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens the bbolt db file, creates it if not exists. The file
+// is locked until Close.
+func OpenBoltStore(file string) (*BoltStore, error) {
+	db, err := bolt.Open(file, 0644, &bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt db: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bookmarksBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create bucket: %w", err)
+	}
+
+	return &BoltStore{db}, nil
+}
+
+func (s *BoltStore) Load() (*Bookmarks, error) {
+	var b *Bookmarks
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		b, _, err = loadFromTx(tx)
+		return err
+	})
+	return b, err
+}
+
+func (s *BoltStore) Save(b *Bookmarks) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bookmarksBucket); err != nil {
+			return err
+		}
+		bucket, err := tx.CreateBucket(bookmarksBucket)
+		if err != nil {
+			return err
+		}
+		for name, r := range b.data {
+			v, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(name), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) Update(fn func(b *Bookmarks) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, before, err := loadFromTx(tx)
+		if err != nil {
+			return err
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+
+		bucket := tx.Bucket(bookmarksBucket)
+		for name, r := range b.data {
+			v, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			if bytes.Equal(v, before[name]) {
+				continue
+			}
+			if err := bucket.Put([]byte(name), v); err != nil {
+				return err
+			}
+		}
+		for name := range before {
+			if _, exists := b.data[name]; exists {
+				continue
+			}
+			if err := bucket.Delete([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// loadFromTx reads all bookmarks, also returns the raw values to find the
+// changed ones.
+func loadFromTx(tx *bolt.Tx) (*Bookmarks, map[string][]byte, error) {
+	b := &Bookmarks{data: map[string]*record{}}
+	raw := map[string][]byte{}
+	err := tx.Bucket(bookmarksBucket).ForEach(func(k, v []byte) error {
+		r := &record{}
+		if err := json.Unmarshal(v, r); err != nil {
+			return fmt.Errorf("failed to unmarshal bookmark %s: %w", k, err)
+		}
+		b.data[string(k)] = r
+		// v is only valid in the transaction.
+		raw[string(k)] = bytes.Clone(v)
+		return nil
+	})
+	return b, raw, err
+}
@@ -16,91 +16,164 @@
 package bookmark
 
 import (
-	"encoding/json"
-	"io"
-	"log"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
+	"unicode"
 )
 
 // Bookmarks contains list, list-with-filter, save, delete and file feature.
-// func will crash if error.
 type Bookmarks struct {
-	data map[string]string
+	data       map[string]*record
+	ignoreCase bool
+}
+
+// New returns empty bookmarks.
+func New() *Bookmarks {
+	return &Bookmarks{
+		data: map[string]*record{},
+	}
 }
 
 func NewBookMarkForTesting() *Bookmarks {
 	return &Bookmarks{
-		data: map[string]string{},
+		data: map[string]*record{},
 	}
 }
 
-// ReadFromFile reads bookmark from file.
-func ReadFromFile(file string) *Bookmarks {
+// Load reads bookmark from file, returns empty bookmarks if the file does not
+// exist.
+func Load(file string) (*Bookmarks, error) {
 	// Read the JSON file.
-	jsonFile, err := os.Open(file)
-	if err != nil {
+	jsonData, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
 		return &Bookmarks{
-			data: map[string]string{},
-		}
+			data: map[string]*record{},
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Close the file when we're done.
-	defer jsonFile.Close()
-
-	// Read the contents of the file into a byte slice.
-	jsonData, err := io.ReadAll(jsonFile)
+	// Unmarshal the JSON data, legacy format will be migrated.
+	data, err := decodeDB(jsonData)
 	if err != nil {
-		log.Fatalf("Failed to read file: %v\n", err)
+		return nil, fmt.Errorf("failed to unmarshal the db file: %w", err)
 	}
 
-	// Create a new hashmap to store the JSON data.
-	data := make(map[string]string)
+	return &Bookmarks{data: data}, nil
+}
 
-	// Unmarshal the JSON data into the hashmap.
-	err = json.Unmarshal(jsonData, &data)
+// Save saves the bookmark to file. It writes to a temp file in the same dir
+// and renames it to the given file, so the file is never half written.
+func (b *Bookmarks) Save(file string) error {
+	// Marshal the hashmap to JSON.
+	jsonData, err := encodeDB(b.data)
 	if err != nil {
-		log.Fatalf("Failed to unmarshal the db file: %v\n", err)
+		return fmt.Errorf("failed to marshal: %w", err)
 	}
 
-	return &Bookmarks{data}
-}
+	// Open the temp file next to the output file, rename is only atomic in the
+	// same file system.
+	dir := filepath.Dir(file)
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(file)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	tmp := tmpFile.Name()
+
+	// Remove the temp file if anything fails, no-op after rename.
+	defer os.Remove(tmp)
+
+	// Write the JSON data to the temp file.
+	_, err = tmpFile.Write(jsonData)
+	if err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
 
-// SaveToFile save the bookmark to file.
-func (b *Bookmarks) SaveToFile(file string) {
-	os.Remove(file)
-	// Open the output file.
-	outputFile, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY, 0644)
+	// Flush the temp file before rename.
+	err = tmpFile.Sync()
 	if err != nil {
-		log.Fatalf("Failed to open file: %v\n", err)
+		tmpFile.Close()
+		return fmt.Errorf("failed to flush file: %w", err)
 	}
 
-	// Close the file when we're done.
-	defer outputFile.Close()
+	err = tmpFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
 
-	// Marshal the hashmap to JSON.
-	jsonData, err := json.Marshal(b.data)
+	// CreateTemp creates file with 0600, keep the mode of existing file.
+	mode := 0644 &^ umask()
+	if fi, err := os.Stat(file); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	err = os.Chmod(tmp, mode)
 	if err != nil {
-		log.Fatalf("Failed to marshal: %v\n", err)
+		return fmt.Errorf("failed to chmod file: %w", err)
 	}
 
-	// Write the JSON data to the output file.
-	_, err = outputFile.Write(jsonData)
+	err = os.Rename(tmp, file)
 	if err != nil {
-		log.Fatalf("Failed to write file: %v\n", err)
+		return fmt.Errorf("failed to rename file: %w", err)
 	}
 
-	// Flush the output file.
-	err = outputFile.Sync()
+	// Flush the dir to persist the rename.
+	err = syncDir(dir)
 	if err != nil {
-		log.Fatalf("Failed to flush file: %v\n", err)
+		return fmt.Errorf("failed to flush dir: %w", err)
 	}
+	return nil
+}
+
+// SetIgnoreCase sets if matching ignores case of keywords and paths.
+func (b *Bookmarks) SetIgnoreCase(ignoreCase bool) {
+	b.ignoreCase = ignoreCase
+}
+
+// IgnoreCase returns if matching ignores case, see SetIgnoreCase.
+func (b *Bookmarks) IgnoreCase() bool {
+	return b.ignoreCase
+}
+
+// fold lower cases s if matching ignores case.
+func (b *Bookmarks) fold(s string) string {
+	if b.ignoreCase {
+		return strings.ToLower(s)
+	}
+	return s
 }
 
 // Bookmark use as result in ListAll() and ListWithFilter()
 type Bookmark struct {
 	Name, Path string
+	Tags       []string
+	Note       string
+	Created    time.Time
+	Updated    time.Time
+	Visits     int
+	LastVisit  time.Time
+}
+
+// bookmark returns the copy of record as Bookmark.
+func (r *record) bookmark(name string) Bookmark {
+	return Bookmark{
+		Name:      name,
+		Path:      r.Path,
+		Tags:      slices.Clone(r.Tags),
+		Note:      r.Note,
+		Created:   r.Created,
+		Updated:   r.Updated,
+		Visits:    r.Visits,
+		LastVisit: r.LastVisit,
+	}
 }
 
 // ListAll lists all saved bookmarks.
@@ -108,9 +181,7 @@ func (b *Bookmarks) ListWithFilters(filters []BookmarkFilter) []Bookmark {
 	res := []Bookmark{}
 	for k, v := range b.data {
 		rejected := false
-		bm := Bookmark{
-			Name: k, Path: v,
-		}
+		bm := v.bookmark(k)
 		for _, f := range filters {
 			if !f.Filter(&bm) {
 				rejected = true
@@ -127,12 +198,23 @@ func (b *Bookmarks) ListWithFilters(filters []BookmarkFilter) []Bookmark {
 	return res
 }
 
+// Get the bookmark with exact name
+func (b *Bookmarks) Get(name string) (*Bookmark, error) {
+	r, exists := b.data[name]
+	if !exists {
+		return nil, notFoundErr(name)
+	}
+	bm := r.bookmark(name)
+	return &bm, nil
+}
+
 // Add a bookmark
 func (b *Bookmarks) Add(name, path string) error {
 	if _, exists := b.data[name]; exists {
 		return alreadyExistsErr(name)
 	}
-	b.data[name] = path
+	t := now()
+	b.data[name] = &record{Path: path, Created: t, Updated: t}
 	return nil
 }
 
@@ -145,18 +227,100 @@ func (b *Bookmarks) Delete(name string) error {
 	return nil
 }
 
+// Rename a bookmark
+func (b *Bookmarks) Rename(old, new string) error {
+	r, exists := b.data[old]
+	if !exists {
+		return notFoundErr(old)
+	}
+	if _, exists := b.data[new]; exists {
+		return alreadyExistsErr(new)
+	}
+	delete(b.data, old)
+	r.Updated = now()
+	b.data[new] = r
+	return nil
+}
+
+// Update changes the path of a bookmark
+func (b *Bookmarks) Update(name, path string) error {
+	r, exists := b.data[name]
+	if !exists {
+		return notFoundErr(name)
+	}
+	r.Path = path
+	r.Updated = now()
+	return nil
+}
+
+// Tag adds and removes tags of a bookmark, tags are kept sorted. It returns
+// false if the tags are not changed.
+func (b *Bookmarks) Tag(name string, add, remove []string) (bool, error) {
+	r, exists := b.data[name]
+	if !exists {
+		return false, notFoundErr(name)
+	}
+	tags := []string{}
+	for _, t := range append(r.Tags, add...) {
+		if !slices.Contains(remove, t) && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	sort.Strings(tags)
+	old := slices.Clone(r.Tags)
+	sort.Strings(old)
+	if slices.Equal(old, tags) {
+		return false, nil
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	r.Tags = tags
+	r.Updated = now()
+	return true, nil
+}
+
+// SetNote sets the note of a bookmark, empty note removes it.
+func (b *Bookmarks) SetNote(name, note string) error {
+	r, exists := b.data[name]
+	if !exists {
+		return notFoundErr(name)
+	}
+	// Notes are printed in one line, in completions and tsv.
+	if strings.IndexFunc(note, unicode.IsControl) >= 0 {
+		return invalidNoteErr(name)
+	}
+	r.Note = note
+	r.Updated = now()
+	return nil
+}
+
+// Visit records a jump to given bookmark, it affects the frecency score.
+func (b *Bookmarks) Visit(name string) error {
+	r, exists := b.data[name]
+	if !exists {
+		return notFoundErr(name)
+	}
+	r.Visits++
+	r.LastVisit = now()
+	return nil
+}
+
 // Match finds the matched bookmark with order.
 // 1. exact match
 // 2. shortest bookmark name with given as prefix, return error if more than 1.
+// Bookmarks with the same length are ranked by frecency, higher score wins.
 func (b *Bookmarks) Match(name string) (*Bookmark, []Bookmark, error) {
-	if path, exists := b.data[name]; exists {
-		return &Bookmark{Name: name, Path: path}, nil, nil
+	name = b.fold(name)
+	if r, exists := b.data[name]; exists {
+		bm := r.bookmark(name)
+		return &bm, nil, nil
 	}
 
 	res := []Bookmark{}
 	for k, v := range b.data {
 		if strings.HasPrefix(k, name) {
-			res = append(res, Bookmark{Name: k, Path: v})
+			res = append(res, v.bookmark(k))
 		}
 	}
 
@@ -171,14 +335,20 @@ func (b *Bookmarks) Match(name string) (*Bookmark, []Bookmark, error) {
 	sort.Slice(res, func(i, j int) bool {
 		li := len(res[i].Name)
 		lj := len(res[j].Name)
-		if li == lj {
-			return res[i].Name < res[j].Name
+		if li != lj {
+			return li < lj
 		}
-		return li < lj
+		si := b.data[res[i].Name].frecency()
+		sj := b.data[res[j].Name].frecency()
+		if si != sj {
+			return si > sj
+		}
+		return res[i].Name < res[j].Name
 	})
 
-	if len(res[0].Name) == len(res[1].Name) {
-		return nil, res, moreThanOneMatchErr(name)
+	if len(res[0].Name) == len(res[1].Name) &&
+		b.data[res[0].Name].frecency() == b.data[res[1].Name].frecency() {
+		return nil, res, moreThanOneMatchErr(name, res)
 	}
 
 	return &res[0], res, nil
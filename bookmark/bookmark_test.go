@@ -15,25 +15,50 @@
 package bookmark
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
 
-func TestReadFromFileFileNotExists(t *testing.T) {
-	got := ReadFromFile(filepath.Join(os.TempDir(), "not-exists"))
-	want := &Bookmarks{data: map[string]string{}}
+func TestLoadFileNotExists(t *testing.T) {
+	got, err := Load(filepath.Join(os.TempDir(), "not-exists"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := &Bookmarks{data: map[string]*record{}}
 	if diff := cmp.Diff(want, got, cmp.AllowUnexported(Bookmarks{})); diff != "" {
 		t.Errorf("-want +got: %v", diff)
 	}
 }
 
-func TestSaveToFile(t *testing.T) {
+func TestLoadBrokenFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "db.json")
+	if err := os.WriteFile(file, []byte("{"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err := Load(file); err == nil {
+		t.Errorf("want error for broken file")
+	}
+}
+
+func TestSaveToDirNotExists(t *testing.T) {
+	b := NewBookMarkForTesting()
+	if err := b.Save(filepath.Join(t.TempDir(), "not-exists", "db.json")); err == nil {
+		t.Errorf("want error for dir not exists")
+	}
+}
+
+func TestSave(t *testing.T) {
 	want := &Bookmarks{
-		data: map[string]string{
-			"aaa": "bbb",
+		data: map[string]*record{
+			"aaa": {Path: "bbb"},
 		},
 	}
 
@@ -47,19 +72,56 @@ func TestSaveToFile(t *testing.T) {
 
 	defer os.Remove(file)
 
-	want.SaveToFile(file)
+	if err := want.Save(file); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
 
-	got := ReadFromFile(file)
+	got, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
 	if diff := cmp.Diff(want, got, cmp.AllowUnexported(Bookmarks{})); diff != "" {
 		t.Errorf("-want +got: %v", diff)
 	}
 }
 
+func TestSaveMode(t *testing.T) {
+	dir := t.TempDir()
+	b := New()
+
+	file := filepath.Join(dir, "new.json")
+	if err := b.Save(file); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	fi, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if want := 0644 &^ umask(); fi.Mode().Perm() != want {
+		t.Errorf("want new file with mode %v, got %v", want, fi.Mode())
+	}
+
+	file = filepath.Join(dir, "private.json")
+	if err := os.WriteFile(file, []byte("{}"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := b.Save(file); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	fi, err = os.Stat(file)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("want mode 0600 kept, got %v", fi.Mode())
+	}
+}
+
 func TestListAll(t *testing.T) {
 	b := &Bookmarks{
-		data: map[string]string{
-			"aaa":  "bbb",
-			"aaa1": "bbb1",
+		data: map[string]*record{
+			"aaa":  {Path: "bbb"},
+			"aaa1": {Path: "bbb1"},
 		},
 	}
 
@@ -76,9 +138,9 @@ func TestListAll(t *testing.T) {
 
 func TestListWithPrefixFilter(t *testing.T) {
 	b := &Bookmarks{
-		data: map[string]string{
-			"aaa":  "bbb",
-			"aaa1": "bbb1",
+		data: map[string]*record{
+			"aaa":  {Path: "bbb"},
+			"aaa1": {Path: "bbb1"},
 		},
 	}
 
@@ -97,10 +159,10 @@ func TestListWithPrefixFilter(t *testing.T) {
 
 func TestListWithChildrenFilter(t *testing.T) {
 	b := &Bookmarks{
-		data: map[string]string{
-			"aaa":  "bbb",
-			"aaa1": "bbb1",
-			"aaa2": "abb",
+		data: map[string]*record{
+			"aaa":  {Path: "bbb"},
+			"aaa1": {Path: "bbb1"},
+			"aaa2": {Path: "abb"},
 		},
 	}
 
@@ -119,10 +181,10 @@ func TestListWithChildrenFilter(t *testing.T) {
 
 func TestListWithPrefixAndChildrenFilter(t *testing.T) {
 	b := &Bookmarks{
-		data: map[string]string{
-			"aaa":  "bbb",
-			"aaa1": "bbb1",
-			"aaa2": "abb",
+		data: map[string]*record{
+			"aaa":  {Path: "bbb"},
+			"aaa1": {Path: "bbb1"},
+			"aaa2": {Path: "abb"},
 		},
 	}
 
@@ -139,10 +201,110 @@ func TestListWithPrefixAndChildrenFilter(t *testing.T) {
 	}
 }
 
+func TestListWithTagFilter(t *testing.T) {
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa":  {Path: "bbb", Tags: []string{"t1", "t2"}},
+			"aaa1": {Path: "bbb1", Tags: []string{"t1"}},
+			"aaa2": {Path: "bbb2"},
+		},
+	}
+
+	got := b.ListWithFilters([]BookmarkFilter{
+		NewTagFilter("t1", "t2"),
+	})
+	want := []Bookmark{
+		{Name: "aaa", Path: "bbb", Tags: []string{"t1", "t2"}},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
+
+func TestCheckStale(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	locked := filepath.Join(dir, "locked")
+	if err := os.Mkdir(locked, 0); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+
+	tests := []struct {
+		n        string
+		path     string
+		want     StaleReason
+		skipRoot bool
+	}{
+		{n: "dir", path: dir, want: NotStale},
+		{n: "missing", path: filepath.Join(dir, "missing"), want: Missing},
+		{n: "file", path: file, want: NotDir},
+		{n: "under file", path: filepath.Join(file, "sub"), want: Missing},
+		{n: "permission denied", path: locked, want: PermissionDenied, skipRoot: true},
+		{n: "stat failed", path: filepath.Join(dir, strings.Repeat("a", 1000)), want: StatFailed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			if tc.skipRoot && os.Geteuid() == 0 {
+				t.Skip("root can enter any dir")
+			}
+			if got := CheckStale(tc.path); got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestListWithStaleFilter(t *testing.T) {
+	dir := t.TempDir()
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa":  {Path: dir},
+			"aaa1": {Path: filepath.Join(dir, "missing")},
+		},
+	}
+
+	got := b.ListWithFilters([]BookmarkFilter{
+		NewStaleFilter(),
+	})
+	want := []Bookmark{
+		{Name: "aaa1", Path: filepath.Join(dir, "missing")},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
+
+func TestGet(t *testing.T) {
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb", Tags: []string{"t1"}},
+		},
+	}
+
+	got, err := b.Get("aaa")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	want := &Bookmark{Name: "aaa", Path: "bbb", Tags: []string{"t1"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	if _, err := b.Get("aa"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want not found error, got %v", err)
+	}
+}
+
 func TestAddFailed(t *testing.T) {
 	b := &Bookmarks{
-		data: map[string]string{
-			"aaa": "bbb",
+		data: map[string]*record{
+			"aaa": {Path: "bbb"},
 		},
 	}
 
@@ -152,9 +314,13 @@ func TestAddFailed(t *testing.T) {
 }
 
 func TestAdd(t *testing.T) {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
 	b := &Bookmarks{
-		data: map[string]string{
-			"aaa": "bbb",
+		data: map[string]*record{
+			"aaa": {Path: "bbb"},
 		},
 	}
 
@@ -163,9 +329,9 @@ func TestAdd(t *testing.T) {
 	}
 
 	want := &Bookmarks{
-		data: map[string]string{
-			"aaa":  "bbb",
-			"aaa1": "ccc",
+		data: map[string]*record{
+			"aaa":  {Path: "bbb"},
+			"aaa1": {Path: "ccc", Created: ts, Updated: ts},
 		},
 	}
 	if diff := cmp.Diff(want, b, cmp.AllowUnexported(Bookmarks{})); diff != "" {
@@ -175,8 +341,8 @@ func TestAdd(t *testing.T) {
 
 func TestDeleteFailed(t *testing.T) {
 	b := &Bookmarks{
-		data: map[string]string{
-			"aaa": "bbb",
+		data: map[string]*record{
+			"aaa": {Path: "bbb"},
 		},
 	}
 
@@ -187,8 +353,8 @@ func TestDeleteFailed(t *testing.T) {
 
 func TestDelete(t *testing.T) {
 	b := &Bookmarks{
-		data: map[string]string{
-			"aaa": "bbb",
+		data: map[string]*record{
+			"aaa": {Path: "bbb"},
 		},
 	}
 
@@ -197,20 +363,72 @@ func TestDelete(t *testing.T) {
 	}
 
 	want := &Bookmarks{
-		data: map[string]string{},
+		data: map[string]*record{},
 	}
 	if diff := cmp.Diff(want, b, cmp.AllowUnexported(Bookmarks{})); diff != "" {
 		t.Errorf("-want +got: %v", diff)
 	}
 }
 
+func TestIsErrType(t *testing.T) {
+	if !IsErrType(notFoundErr("aaa"), NotFound) {
+		t.Errorf("want true for same type")
+	}
+	if IsErrType(notFoundErr("aaa"), AlreadyExists) {
+		t.Errorf("want false for other type")
+	}
+	if IsErrType(os.ErrNotExist, NotFound) {
+		t.Errorf("want false for foreign error")
+	}
+}
+
+func TestErrorsIsAndAs(t *testing.T) {
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aab1": {Path: "ccc"},
+			"aab2": {Path: "ddd"},
+		},
+	}
+
+	_, _, err := b.Match("aab")
+	wrapped := fmt.Errorf("find: %w", err)
+	if !errors.Is(wrapped, ErrAmbiguous) {
+		t.Errorf("want wrapped error is ErrAmbiguous")
+	}
+	if errors.Is(wrapped, ErrNotFound) {
+		t.Errorf("want wrapped error is not ErrNotFound")
+	}
+	if !IsErrType(wrapped, MoreThanOneMatch) {
+		t.Errorf("want wrapped error has type MoreThanOneMatch")
+	}
+
+	var e *Err
+	if !errors.As(wrapped, &e) {
+		t.Fatalf("want wrapped error as *Err")
+	}
+	want := []Bookmark{{Name: "aab1", Path: "ccc"}, {Name: "aab2", Path: "ddd"}}
+	if diff := cmp.Diff(want, e.Candidates()); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	if err := b.Delete("aaa"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want ErrNotFound, got %v", err)
+	}
+	if err := b.Add("aab1", "eee"); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("want ErrAlreadyExists, got %v", err)
+	}
+	if _, _, err := b.Match("b"); !errors.Is(err, ErrPrefixNotFound) {
+		t.Errorf("want ErrPrefixNotFound, got %v", err)
+	}
+}
+
 func TestMatch(t *testing.T) {
 	b := &Bookmarks{
-		data: map[string]string{
-			"aaa":  "bbb",
-			"aab1": "ccc",
-			"aab2": "ddd",
-			"abc":  "eee",
+		data: map[string]*record{
+			"aaa":  {Path: "bbb"},
+			"aab1": {Path: "ccc"},
+			"aab2": {Path: "ddd"},
+			"abc":  {Path: "eee"},
 		},
 	}
 
@@ -268,3 +486,277 @@ func TestMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadLegacyFormat(t *testing.T) {
+	f, err := os.CreateTemp("", "bmtest")
+	if err != nil {
+		t.Fatalf("CreateTemp: %v", err)
+	}
+
+	file := f.Name()
+	defer os.Remove(file)
+
+	if _, err := f.WriteString(`{"aaa":"bbb"}`); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+	f.Close()
+
+	got, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb"},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(Bookmarks{})); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
+
+func TestRenameFailed(t *testing.T) {
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa":  {Path: "bbb"},
+			"aaa1": {Path: "ccc"},
+		},
+	}
+
+	if err := b.Rename("aaa2", "aaa3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want not found error, got %v", err)
+	}
+	if err := b.Rename("aaa", "aaa1"); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("want already exists error, got %v", err)
+	}
+}
+
+func TestRename(t *testing.T) {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb", Visits: 1},
+		},
+	}
+
+	if err := b.Rename("aaa", "ccc"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+
+	want := &Bookmarks{
+		data: map[string]*record{
+			"ccc": {Path: "bbb", Updated: ts, Visits: 1},
+		},
+	}
+	if diff := cmp.Diff(want, b, cmp.AllowUnexported(Bookmarks{})); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
+
+func TestUpdateFailed(t *testing.T) {
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb"},
+		},
+	}
+
+	if err := b.Update("aaa1", "ccc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want not found error, got %v", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb"},
+		},
+	}
+
+	if err := b.Update("aaa", "ccc"); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	want := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "ccc", Updated: ts},
+		},
+	}
+	if diff := cmp.Diff(want, b, cmp.AllowUnexported(Bookmarks{})); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
+
+func TestTag(t *testing.T) {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb", Tags: []string{"t1", "t3"}},
+		},
+	}
+
+	if _, err := b.Tag("aaa1", []string{"t1"}, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want not found error, got %v", err)
+	}
+
+	if changed, err := b.Tag("aaa", []string{"t2", "t1", "t0", "t0"}, []string{"t3", "t4"}); err != nil || !changed {
+		t.Fatalf("want changed, got %v %v", changed, err)
+	}
+	want := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb", Updated: ts, Tags: []string{"t0", "t1", "t2"}},
+		},
+	}
+	if diff := cmp.Diff(want, b, cmp.AllowUnexported(Bookmarks{})); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	// No change keeps updated.
+	later := ts.Add(time.Hour)
+	now = func() time.Time { return later }
+	if changed, err := b.Tag("aaa", []string{"t1"}, []string{"t3"}); err != nil || changed {
+		t.Fatalf("want not changed, got %v %v", changed, err)
+	}
+	if diff := cmp.Diff(want, b, cmp.AllowUnexported(Bookmarks{})); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	if changed, err := b.Tag("aaa", nil, []string{"t0", "t1", "t2"}); err != nil || !changed {
+		t.Fatalf("want changed, got %v %v", changed, err)
+	}
+	want = &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb", Updated: later},
+		},
+	}
+	if diff := cmp.Diff(want, b, cmp.AllowUnexported(Bookmarks{})); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
+
+func TestSetNote(t *testing.T) {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb"},
+		},
+	}
+
+	if err := b.SetNote("aaa1", "note"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want not found error, got %v", err)
+	}
+	for _, note := range []string{"a\nb", "a\tb", "a\x1b[31m"} {
+		if err := b.SetNote("aaa", note); !errors.Is(err, ErrInvalidNote) {
+			t.Errorf("want invalid note error for %q, got %v", note, err)
+		}
+	}
+	if err := b.SetNote("aaa", "note"); err != nil {
+		t.Fatalf("SetNote failed: %v", err)
+	}
+
+	want := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb", Updated: ts, Note: "note"},
+		},
+	}
+	if diff := cmp.Diff(want, b, cmp.AllowUnexported(Bookmarks{})); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
+
+func TestVisit(t *testing.T) {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb", Visits: 1},
+		},
+	}
+
+	if err := b.Visit("aaa"); err != nil {
+		t.Fatalf("Visit failed: %v", err)
+	}
+	if err := b.Visit("aaa1"); err == nil || !IsErrType(err, NotFound) {
+		t.Errorf("want not found error")
+	}
+
+	want := &Bookmarks{
+		data: map[string]*record{
+			"aaa": {Path: "bbb", Visits: 2, LastVisit: ts},
+		},
+	}
+	if diff := cmp.Diff(want, b, cmp.AllowUnexported(Bookmarks{})); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
+
+func TestMatchWithFrecency(t *testing.T) {
+	ts := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aab1": {Path: "ccc", Visits: 10, LastVisit: ts.Add(-30 * 24 * time.Hour)},
+			"aab2": {Path: "ddd", Visits: 2, LastVisit: ts.Add(-time.Minute)},
+			"aab3": {Path: "eee"},
+		},
+	}
+
+	got1, got2, err := b.Match("aab")
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	aab1 := Bookmark{Name: "aab1", Path: "ccc", Visits: 10, LastVisit: ts.Add(-30 * 24 * time.Hour)}
+	aab2 := Bookmark{Name: "aab2", Path: "ddd", Visits: 2, LastVisit: ts.Add(-time.Minute)}
+	aab3 := Bookmark{Name: "aab3", Path: "eee"}
+
+	want1 := &aab2
+	if diff := cmp.Diff(want1, got1); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+	want2 := []Bookmark{aab2, aab1, aab3}
+	if diff := cmp.Diff(want2, got2); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
+
+func TestFrecency(t *testing.T) {
+	ts := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		n    string
+		r    record
+		want float64
+	}{
+		{n: "never visited", r: record{}, want: 0},
+		{n: "within 1 hour", r: record{Visits: 2, LastVisit: ts.Add(-time.Minute)}, want: 8},
+		{n: "within 1 day", r: record{Visits: 2, LastVisit: ts.Add(-2 * time.Hour)}, want: 4},
+		{n: "within 1 week", r: record{Visits: 2, LastVisit: ts.Add(-48 * time.Hour)}, want: 1},
+		{n: "long ago", r: record{Visits: 2, LastVisit: ts.Add(-30 * 24 * time.Hour)}, want: 0.5},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			if got := tc.r.frecency(); got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
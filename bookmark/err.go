@@ -15,8 +15,8 @@
 package bookmark
 
 import (
+	"errors"
 	"fmt"
-	"log"
 )
 
 type BookmarkErrType int
@@ -27,22 +27,56 @@ const (
 	PrefixNotFound
 	AlreadyExists
 	MoreThanOneMatch
+	FuzzyNotFound
+	PathNotFound
+	QueryNotFound
+	InvalidNote
+)
+
+// Sentinel errors for errors.Is, errors returned from this package wrap one of
+// them.
+var (
+	ErrNotFound       = &Err{errType: NotFound, message: "bookmark not found"}
+	ErrPrefixNotFound = &Err{errType: PrefixNotFound, message: "bookmark with prefix not found"}
+	ErrAlreadyExists  = &Err{errType: AlreadyExists, message: "bookmark already exists"}
+	ErrAmbiguous      = &Err{errType: MoreThanOneMatch, message: "bookmark has more than 1 matches"}
+	ErrFuzzyNotFound  = &Err{errType: FuzzyNotFound, message: "bookmark fuzzy matching not found"}
+	ErrPathNotFound   = &Err{errType: PathNotFound, message: "bookmark with path matching not found"}
+	ErrQueryNotFound  = &Err{errType: QueryNotFound, message: "bookmark matching all keywords not found"}
+	ErrInvalidNote    = &Err{errType: InvalidNote, message: "note has control characters"}
 )
 
 // Err for bookmark
 type Err struct {
-	errType BookmarkErrType
-	message string
+	errType    BookmarkErrType
+	message    string
+	candidates []Bookmark
+	err        error
 }
 
 func (e *Err) Error() string {
 	return e.message
 }
 
+// Unwrap returns the sentinel error of the same type.
+func (e *Err) Unwrap() error {
+	return e.err
+}
+
+// Candidates returns the ranked matched bookmarks of MoreThanOneMatch error.
+func (e *Err) Candidates() []Bookmark {
+	return e.candidates
+}
+
+// Chooser picks one of the ranked candidates of MoreThanOneMatch error.
+type Chooser func(candidates []Bookmark) (*Bookmark, error)
+
+// IsErrType returns true if e is or wraps *Err with given type, false for
+// other errors.
 func IsErrType(e error, t BookmarkErrType) bool {
-	er, ok := e.(*Err)
-	if !ok {
-		log.Fatalf("Error is not BookmarkErr")
+	var er *Err
+	if !errors.As(e, &er) {
+		return false
 	}
 	return er.errType == t
 }
@@ -51,6 +85,7 @@ func notFoundErr(name string) *Err {
 	return &Err{
 		errType: NotFound,
 		message: fmt.Sprintf("bookmark %v not found", name),
+		err:     ErrNotFound,
 	}
 }
 
@@ -58,6 +93,39 @@ func prefixNotFoundErr(name string) *Err {
 	return &Err{
 		errType: PrefixNotFound,
 		message: fmt.Sprintf("bookmark with prefix %q not found", name),
+		err:     ErrPrefixNotFound,
+	}
+}
+
+func fuzzyNotFoundErr(name string) *Err {
+	return &Err{
+		errType: FuzzyNotFound,
+		message: fmt.Sprintf("bookmark fuzzy matching %q not found", name),
+		err:     ErrFuzzyNotFound,
+	}
+}
+
+func pathNotFoundErr(query string) *Err {
+	return &Err{
+		errType: PathNotFound,
+		message: fmt.Sprintf("bookmark with path matching %q not found", query),
+		err:     ErrPathNotFound,
+	}
+}
+
+func queryNotFoundErr(query string) *Err {
+	return &Err{
+		errType: QueryNotFound,
+		message: fmt.Sprintf("bookmark matching all keywords %q not found", query),
+		err:     ErrQueryNotFound,
+	}
+}
+
+func invalidNoteErr(name string) *Err {
+	return &Err{
+		errType: InvalidNote,
+		message: fmt.Sprintf("note of bookmark %v has control characters like newline or tab", name),
+		err:     ErrInvalidNote,
 	}
 }
 
@@ -65,12 +133,50 @@ func alreadyExistsErr(name string) *Err {
 	return &Err{
 		errType: AlreadyExists,
 		message: fmt.Sprintf("bookmark %v already exists", name),
+		err:     ErrAlreadyExists,
+	}
+}
+
+func moreThanOneMatchErr(name string, candidates []Bookmark) *Err {
+	return &Err{
+		errType:    MoreThanOneMatch,
+		message:    fmt.Sprintf("bookmark with %q prefix has more than 1 matches", name),
+		candidates: candidates,
+		err:        ErrAmbiguous,
+	}
+}
+
+func subdirNotFoundErr(path string) *Err {
+	return &Err{
+		errType: PrefixNotFound,
+		message: fmt.Sprintf("sub dir with prefix %q not found", path),
+		err:     ErrPrefixNotFound,
+	}
+}
+
+func subdirMoreThanOneMatchErr(path string, candidates []Bookmark) *Err {
+	return &Err{
+		errType:    MoreThanOneMatch,
+		message:    fmt.Sprintf("sub dir with %q prefix has more than 1 matches", path),
+		candidates: candidates,
+		err:        ErrAmbiguous,
+	}
+}
+
+func pathMoreThanOneMatchErr(query string, candidates []Bookmark) *Err {
+	return &Err{
+		errType:    MoreThanOneMatch,
+		message:    fmt.Sprintf("bookmark with path matching %q has more than 1 matches", query),
+		candidates: candidates,
+		err:        ErrAmbiguous,
 	}
 }
 
-func moreThanOneMatchErr(name string) *Err {
+func queryMoreThanOneMatchErr(query string, candidates []Bookmark) *Err {
 	return &Err{
-		errType: MoreThanOneMatch,
-		message: fmt.Sprintf("bookmark with %q prefix has more than 1 matches", name),
+		errType:    MoreThanOneMatch,
+		message:    fmt.Sprintf("bookmark matching all keywords %q has more than 1 matches", query),
+		candidates: candidates,
+		err:        ErrAmbiguous,
 	}
 }
@@ -0,0 +1,99 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Paths can be stored portable as "~/..." or "$VAR/...", so the db can be
+// synced between machines with different home dirs or mount points.
+
+// IsPortable returns true if path starts with "~" or "$VAR".
+func IsPortable(path string) bool {
+	return path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "$")
+}
+
+// ErrUnsetVar is returned if the env var or $HOME of a portable path is not
+// set, or is not an absolute path.
+var ErrUnsetVar = errors.New("env var not set")
+
+// ExpandPath expands the leading "~" to $HOME and "$VAR" or "${VAR}" to the
+// env, other paths are returned as is. It fails with ErrUnsetVar instead of
+// returning a relative path.
+func ExpandPath(path string) (string, error) {
+	return ExpandPathWith(path, os.Getenv)
+}
+
+// ExpandPathWith expands path like ExpandPath with the env from getenv.
+func ExpandPathWith(path string, getenv func(string) string) (string, error) {
+	var name, rest string
+	switch {
+	case path == "~" || strings.HasPrefix(path, "~/"):
+		name, rest = "HOME", path[1:]
+	case strings.HasPrefix(path, "$"):
+		var first string
+		first, rest, _ = strings.Cut(path[1:], "/")
+		name = strings.TrimSuffix(strings.TrimPrefix(first, "{"), "}")
+	default:
+		return path, nil
+	}
+	value := getenv(name)
+	if !filepath.IsAbs(value) {
+		return "", fmt.Errorf("%w: $%v of %v", ErrUnsetVar, name, path)
+	}
+	return filepath.Join(value, rest), nil
+}
+
+// TryExpandPath expands path like ExpandPath, returns path as is if it can
+// not be expanded. It is for showing, sorting and comparing paths.
+func TryExpandPath(path string) string {
+	if expanded, err := ExpandPath(path); err == nil {
+		return expanded
+	}
+	return path
+}
+
+// PortablePath returns path relative to the longest of the env vars and $HOME
+// containing it, as "$VAR/..." or "~/...". Other paths are returned as is.
+func PortablePath(path string, vars []string) string {
+	best, bestPrefix := "", ""
+	try := func(dir, prefix string) {
+		if !filepath.IsAbs(dir) {
+			return
+		}
+		dir = filepath.Clean(dir)
+		if len(dir) > len(best) && isUnder(path, dir) {
+			best, bestPrefix = dir, prefix
+		}
+	}
+	for _, v := range vars {
+		try(os.Getenv(v), "$"+v)
+	}
+	try(os.Getenv("HOME"), "~")
+	if best == "" {
+		return path
+	}
+	return bestPrefix + strings.TrimPrefix(path, best)
+}
+
+// isUnder returns true if path is dir or in dir.
+func isUnder(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/")
+}
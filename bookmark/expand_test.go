@@ -0,0 +1,151 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	t.Setenv("WORKSPACE", "/mnt/ws")
+	t.Setenv("EMPTY", "")
+	t.Setenv("RELATIVE", "ws")
+	t.Setenv("UNSET", "")
+	os.Unsetenv("UNSET")
+
+	tests := []struct {
+		path string
+		want string
+		err  error
+	}{
+		{path: "~", want: "/home/u"},
+		{path: "~/src/api", want: "/home/u/src/api"},
+		{path: "$WORKSPACE/api", want: "/mnt/ws/api"},
+		{path: "${WORKSPACE}/api", want: "/mnt/ws/api"},
+		{path: "$WORKSPACE", want: "/mnt/ws"},
+		{path: "/tmp/a$b", want: "/tmp/a$b"},
+		{path: "/tmp/~", want: "/tmp/~"},
+		{path: "$UNSET/api", err: ErrUnsetVar},
+		{path: "${UNSET}", err: ErrUnsetVar},
+		{path: "$EMPTY/api", err: ErrUnsetVar},
+		{path: "$RELATIVE/api", err: ErrUnsetVar},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			got, err := ExpandPath(tc.path)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("want err %v, got %v", tc.err, err)
+			}
+			if got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	t.Setenv("HOME", "")
+	if _, err := ExpandPath("~/api"); !errors.Is(err, ErrUnsetVar) {
+		t.Errorf("want ErrUnsetVar without HOME, got %v", err)
+	}
+}
+
+func TestPortablePath(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	t.Setenv("WORKSPACE", "/home/u/ws")
+	t.Setenv("GOPATH", "/opt/go/")
+	t.Setenv("EMPTY", "")
+
+	vars := []string{"WORKSPACE", "GOPATH", "EMPTY"}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/home/u", want: "~"},
+		{path: "/home/u/src", want: "~/src"},
+		{path: "/home/u2/src", want: "/home/u2/src"},
+		{path: "/home/u/ws/api", want: "$WORKSPACE/api"},
+		{path: "/opt/go/src/x", want: "$GOPATH/src/x"},
+		{path: "/tmp", want: "/tmp"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			got := PortablePath(tc.path, vars)
+			if got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+			if expanded, err := ExpandPath(got); err != nil || expanded != tc.path {
+				t.Errorf("want %v expanded back to %v, got %v %v", got, tc.path, expanded, err)
+			}
+		})
+	}
+}
+
+func TestCheckStaleUnsetVar(t *testing.T) {
+	t.Setenv("EMPTY", "")
+	t.Setenv("UNSET", "")
+	os.Unsetenv("UNSET")
+	// Not checked relative to cwd.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd failed: %v", err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Chdir failed: %v", err)
+	}
+	if err := os.Mkdir("api", 0755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+
+	for _, path := range []string{"$UNSET/api", "$EMPTY/api"} {
+		got := CheckStale(path)
+		if got != UnsetVar {
+			t.Errorf("want %v of %v, got %v", UnsetVar, path, got)
+		}
+		if got.Removable() {
+			t.Errorf("want %v not removable", got)
+		}
+	}
+}
+
+func TestListWithPortableChildrenFilter(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	t.Setenv("WORKSPACE", "/home/u/ws")
+	b := &Bookmarks{
+		data: map[string]*record{
+			"api":   {Path: "$WORKSPACE/api"},
+			"docs":  {Path: "~/docs"},
+			"tmp":   {Path: "/tmp"},
+			"unset": {Path: "$UNSET/home/u"},
+		},
+	}
+
+	got := b.ListWithFilters([]BookmarkFilter{
+		NewChildrenDirFilter("/home/u"),
+	})
+	want := []Bookmark{
+		{Name: "api", Path: "$WORKSPACE/api"},
+		{Name: "docs", Path: "~/docs"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
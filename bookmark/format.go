@@ -0,0 +1,100 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// dbVersion is the version of db file format written by this version.
+// Version 1 is the legacy flat map from name to path without version field.
+const dbVersion = 2
+
+// db is the on-disk format of the db file.
+type db struct {
+	Version   int                `json:"version"`
+	Bookmarks map[string]*record `json:"bookmarks"`
+}
+
+// record is what we store for each bookmark.
+type record struct {
+	Path      string    `json:"path"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	Tags      []string  `json:"tags,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	Visits    int       `json:"visits,omitempty"`
+	LastVisit time.Time `json:"last_visit"`
+}
+
+// UnmarshalJSON accepts both record object and the plain path string stored
+// by version 1.
+func (r *record) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*r = record{Path: path}
+		return nil
+	}
+
+	// Use another type to avoid calling UnmarshalJSON recursively.
+	type plain record
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// decodeDB decodes the db file content, migrates legacy format if needed.
+func decodeDB(data []byte) (map[string]*record, error) {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	// Version 1 may have a bookmark named "version", but its value is a string.
+	var version int
+	if err := json.Unmarshal(raw["version"], &version); err != nil {
+		return decodeLegacyDB(data)
+	}
+
+	if version > dbVersion {
+		return nil, fmt.Errorf("db version %v is newer than supported version %v", version, dbVersion)
+	}
+
+	d := db{}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	if d.Bookmarks == nil {
+		d.Bookmarks = map[string]*record{}
+	}
+	return d.Bookmarks, nil
+}
+
+// decodeLegacyDB decodes version 1 db file.
+func decodeLegacyDB(data []byte) (map[string]*record, error) {
+	res := map[string]*record{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// encodeDB encodes the bookmarks in latest format.
+func encodeDB(data map[string]*record) ([]byte, error) {
+	return json.Marshal(&db{
+		Version:   dbVersion,
+		Bookmarks: data,
+	})
+}
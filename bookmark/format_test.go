@@ -0,0 +1,113 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeDB(t *testing.T) {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		n       string
+		input   string
+		want    map[string]*record
+		wantErr bool
+	}{
+		{
+			n:     "legacy",
+			input: `{"aaa":"bbb","version":"ccc"}`,
+			want: map[string]*record{
+				"aaa":     {Path: "bbb"},
+				"version": {Path: "ccc"},
+			},
+		},
+		{
+			n:     "empty legacy",
+			input: `{}`,
+			want:  map[string]*record{},
+		},
+		{
+			n: "version 2",
+			input: `{"version":2,"bookmarks":{"aaa":{"path":"bbb",` +
+				`"created":"2023-01-01T00:00:00Z","updated":"2023-01-01T00:00:00Z",` +
+				`"tags":["t1"],"note":"n","visits":3,"last_visit":"2023-01-01T00:00:00Z"}}}`,
+			want: map[string]*record{
+				"aaa": {
+					Path: "bbb", Created: ts, Updated: ts,
+					Tags: []string{"t1"}, Note: "n", Visits: 3, LastVisit: ts,
+				},
+			},
+		},
+		{
+			n:     "version 2 without bookmarks",
+			input: `{"version":2}`,
+			want:  map[string]*record{},
+		},
+		{
+			n:       "newer version",
+			input:   `{"version":3,"bookmarks":{}}`,
+			wantErr: true,
+		},
+		{
+			n:       "broken",
+			input:   `{"aaa":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			got, err := decodeDB([]byte(tc.input))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want err %v, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+}
+
+func TestEncodeDB(t *testing.T) {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := map[string]*record{
+		"aaa": {Path: "bbb", Created: ts, Updated: ts, Tags: []string{"t1"}},
+	}
+
+	b, err := encodeDB(data)
+	if err != nil {
+		t.Fatalf("encodeDB failed: %v", err)
+	}
+
+	want := `{"version":2,"bookmarks":{"aaa":{"path":"bbb",` +
+		`"created":"2023-01-01T00:00:00Z","updated":"2023-01-01T00:00:00Z",` +
+		`"tags":["t1"],"last_visit":"0001-01-01T00:00:00Z"}}}`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	got, err := decodeDB(b)
+	if err != nil {
+		t.Fatalf("decodeDB failed: %v", err)
+	}
+	if diff := cmp.Diff(data, got); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
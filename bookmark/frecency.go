@@ -0,0 +1,51 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import "time"
+
+// now is replaced in tests.
+var now = time.Now
+
+// frecency returns the score of the bookmark combining how often and how
+// recently it is visited, same weights as zoxide:
+// - last visit within 1 hour: visits * 4
+// - last visit within 1 day: visits * 2
+// - last visit within 1 week: visits / 2
+// - otherwise: visits / 4
+func (r *record) frecency() float64 {
+	if r.Visits == 0 {
+		return 0
+	}
+
+	visits := float64(r.Visits)
+	age := now().Sub(r.LastVisit)
+	switch {
+	case age < time.Hour:
+		return visits * 4
+	case age < 24*time.Hour:
+		return visits * 2
+	case age < 7*24*time.Hour:
+		return visits / 2
+	default:
+		return visits / 4
+	}
+}
+
+// Frecency returns the frecency score of the bookmark, see record.frecency.
+func (b *Bookmark) Frecency() float64 {
+	r := record{Visits: b.Visits, LastVisit: b.LastVisit}
+	return r.frecency()
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !unix
+
+package bookmark
+
+import (
+	"io/fs"
+	"os"
+)
+
+// Advisory lock is not supported, concurrent writes may lose data.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
+
+// syncDir is not supported, rename is persisted by the file system.
+func syncDir(dir string) error {
+	return nil
+}
+
+// canEnter is not supported, assume the dir is searchable if stat succeeds.
+func canEnter(dir string) bool {
+	return true
+}
+
+// umask is not supported, files are created with the given mode.
+func umask() fs.FileMode {
+	return 0
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Scores for fuzzy matching, similar to fzf.
+const (
+	scoreMatch          = 16
+	bonusBoundary       = 8
+	bonusFirstChar      = 2 // multiplier of boundary bonus for first char.
+	bonusConsecutive    = 8
+	penaltyGapStart     = 3
+	penaltyGapExtension = 1
+
+	impossible = -1 << 30
+)
+
+// fuzzyScore returns the best score of pattern as subsequence of s, false if
+// pattern is not a subsequence of s.
+func fuzzyScore(pattern, s string) (int, bool) {
+	p := []rune(pattern)
+	r := []rune(s)
+	if len(p) == 0 {
+		return 0, true
+	}
+	if len(p) > len(r) {
+		return 0, false
+	}
+
+	// prev[j] is the best score of p[:i] with p[i-1] matched at r[j].
+	prev := make([]int, len(r))
+	curr := make([]int, len(r))
+	for j := range r {
+		prev[j] = impossible
+		if r[j] == p[0] {
+			prev[j] = scoreMatch + boundaryBonus(r, j)*bonusFirstChar
+		}
+	}
+
+	for i := 1; i < len(p); i++ {
+		for j := range r {
+			curr[j] = impossible
+			if r[j] != p[i] {
+				continue
+			}
+			for k := 0; k < j; k++ {
+				if prev[k] == impossible {
+					continue
+				}
+				score := prev[k] + scoreMatch + boundaryBonus(r, j)
+				if k == j-1 {
+					score += bonusConsecutive
+				} else {
+					score -= penaltyGapStart + penaltyGapExtension*(j-k-2)
+				}
+				if score > curr[j] {
+					curr[j] = score
+				}
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	best := impossible
+	for _, score := range prev {
+		if score > best {
+			best = score
+		}
+	}
+	return best, best != impossible
+}
+
+// boundaryBonus gives bonus to char at word boundary, eg. start of the name or
+// letter after digit.
+func boundaryBonus(r []rune, j int) int {
+	if j == 0 {
+		return bonusBoundary
+	}
+	if unicode.IsLetter(r[j]) != unicode.IsLetter(r[j-1]) {
+		return bonusBoundary
+	}
+	return 0
+}
+
+// FuzzyMatch finds the matched bookmark with order.
+// 1. exact match
+// 2. bookmark name contains given as subsequence, ranked by fuzzy score,
+// shorter name and frecency, return error if the top 2 tie.
+func (b *Bookmarks) FuzzyMatch(pattern string) (*Bookmark, []Bookmark, error) {
+	pattern = b.fold(pattern)
+	if r, exists := b.data[pattern]; exists {
+		bm := r.bookmark(pattern)
+		return &bm, nil, nil
+	}
+
+	res := []Bookmark{}
+	scores := map[string]int{}
+	for k, v := range b.data {
+		if score, ok := fuzzyScore(pattern, k); ok {
+			res = append(res, v.bookmark(k))
+			scores[k] = score
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, nil, fuzzyNotFoundErr(pattern)
+	}
+
+	if len(res) == 1 {
+		return &res[0], res, nil
+	}
+
+	tie := func(i, j int) bool {
+		ni := res[i].Name
+		nj := res[j].Name
+		return scores[ni] == scores[nj] && len(ni) == len(nj) &&
+			b.data[ni].frecency() == b.data[nj].frecency()
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		ni := res[i].Name
+		nj := res[j].Name
+		if scores[ni] != scores[nj] {
+			return scores[ni] > scores[nj]
+		}
+		if len(ni) != len(nj) {
+			return len(ni) < len(nj)
+		}
+		si := b.data[ni].frecency()
+		sj := b.data[nj].frecency()
+		if si != sj {
+			return si > sj
+		}
+		return ni < nj
+	})
+
+	if tie(0, 1) {
+		return nil, res, moreThanOneMatchErr(pattern, res)
+	}
+
+	return &res[0], res, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		n       string
+		pattern string
+		s       string
+		want    int
+		ok      bool
+	}{
+		{n: "empty pattern", pattern: "", s: "abc", want: 0, ok: true},
+		{n: "not subsequence", pattern: "ba", s: "abc", ok: false},
+		{n: "longer than s", pattern: "abcd", s: "abc", ok: false},
+		{n: "prefix", pattern: "ab", s: "abc", want: 16 + 16 + 16 + 8, ok: true},
+		{n: "consecutive in middle", pattern: "bc", s: "abc", want: 16 + 16 + 8, ok: true},
+		{n: "gap", pattern: "ac", s: "abc", want: 16 + 16 + 16 - 3, ok: true},
+		{n: "prefer consecutive", pattern: "bil", s: "svcbilbil", want: 16 + 16 + 16 + 8 + 8, ok: true},
+		{n: "boundary after digit", pattern: "b", s: "a1b", want: 16 + 16, ok: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			got, ok := fuzzyScore(tc.pattern, tc.s)
+			if ok != tc.ok {
+				t.Fatalf("want ok %v, got %v", tc.ok, ok)
+			}
+			if ok && got != tc.want {
+				t.Errorf("want score %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	b := &Bookmarks{
+		data: map[string]*record{
+			"aaa":        {Path: "bbb"},
+			"aab1":       {Path: "ccc"},
+			"aab2":       {Path: "ddd"},
+			"abxxxx":     {Path: "ggg"},
+			"svcauth":    {Path: "eee"},
+			"svcbilling": {Path: "fff"},
+		},
+	}
+
+	tests := []struct {
+		n       string
+		input   string
+		result1 *Bookmark
+		result2 []Bookmark
+		et      BookmarkErrType
+	}{
+		{n: "exact match", input: "aaa", result1: &Bookmark{Name: "aaa", Path: "bbb"}, et: NoErr},
+		{n: "not found", input: "zz", et: FuzzyNotFound},
+		{
+			n:       "match in middle",
+			input:   "bil",
+			result1: &Bookmark{Name: "svcbilling", Path: "fff"},
+			result2: []Bookmark{{Name: "svcbilling", Path: "fff"}},
+			et:      NoErr,
+		},
+		{
+			n:       "higher score wins",
+			input:   "ab",
+			result1: &Bookmark{Name: "abxxxx", Path: "ggg"},
+			result2: []Bookmark{
+				{Name: "abxxxx", Path: "ggg"}, {Name: "aab1", Path: "ccc"}, {Name: "aab2", Path: "ddd"}},
+			et: NoErr,
+		},
+		{
+			n:       "shorter wins",
+			input:   "aa",
+			result1: &Bookmark{Name: "aaa", Path: "bbb"},
+			result2: []Bookmark{
+				{Name: "aaa", Path: "bbb"}, {Name: "aab1", Path: "ccc"}, {Name: "aab2", Path: "ddd"}},
+			et: NoErr,
+		},
+		{
+			n:       "match more than 1",
+			input:   "aab",
+			result2: []Bookmark{{Name: "aab1", Path: "ccc"}, {Name: "aab2", Path: "ddd"}},
+			et:      MoreThanOneMatch,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			got1, got2, err := b.FuzzyMatch(tc.input)
+			if diff := cmp.Diff(tc.result1, got1); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+			if diff := cmp.Diff(tc.result2, got2); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+			if err != nil && tc.et == NoErr {
+				t.Errorf("want no err, got %v", err)
+			}
+			if err == nil && tc.et != NoErr {
+				t.Errorf("want err type %v, but no err", tc.et)
+			}
+			if err != nil {
+				et := err.(*Err).errType
+				if et != tc.et {
+					t.Errorf("want err type %v, got err type %v", tc.et, et)
+				}
+			}
+		})
+	}
+}
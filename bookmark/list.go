@@ -14,7 +14,14 @@
 
 package bookmark
 
-import "strings"
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"syscall"
+)
 
 type BookmarkFilter interface {
 	Filter(*Bookmark) bool
@@ -40,6 +47,108 @@ func NewChildrenDirFilter(dir string) *ChildrenDirFilter {
 	return &ChildrenDirFilter{dir}
 }
 
+// Filter compares the expanded paths, see ExpandPath. Paths can not be
+// expanded are not children.
 func (f *ChildrenDirFilter) Filter(b *Bookmark) bool {
-	return strings.HasPrefix(b.Path, f.dir)
+	path, err := ExpandPath(b.Path)
+	if err != nil {
+		return false
+	}
+	dir, err := ExpandPath(f.dir)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(path, dir)
+}
+
+// TagFilter keeps only the bookmarks have all given tags.
+type TagFilter struct {
+	tags []string
+}
+
+func NewTagFilter(tags ...string) *TagFilter {
+	return &TagFilter{tags}
+}
+
+func (f *TagFilter) Filter(b *Bookmark) bool {
+	for _, t := range f.tags {
+		if !slices.Contains(b.Tags, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// StaleReason tells why the path of a bookmark is not usable.
+type StaleReason int
+
+const (
+	NotStale StaleReason = iota
+	Missing
+	NotDir
+	PermissionDenied
+	// StatFailed is other errors of stat, eg. EIO on network mounts, it may be
+	// transient.
+	StatFailed
+	// UnsetVar is the env var of portable path is not set, see ExpandPath.
+	UnsetVar
+)
+
+func (r StaleReason) String() string {
+	switch r {
+	case NotStale:
+		return "ok"
+	case Missing:
+		return "missing"
+	case NotDir:
+		return "not a directory"
+	case PermissionDenied:
+		return "permission denied"
+	case StatFailed:
+		return "stat failed"
+	case UnsetVar:
+		return "env var not set"
+	}
+	return "unknown"
+}
+
+// Removable returns true if the path surely can not be cd to, prune only
+// removes these.
+func (r StaleReason) Removable() bool {
+	return r == Missing || r == NotDir || r == PermissionDenied
+}
+
+// CheckStale stats the given path to see if it can be cd to, portable paths
+// are expanded first.
+func CheckStale(path string) StaleReason {
+	path, err := ExpandPath(path)
+	if err != nil {
+		return UnsetVar
+	}
+	fi, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return PermissionDenied
+	// ENOTDIR means a parent is not a dir, so the path does not exist.
+	case errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR):
+		return Missing
+	case err != nil:
+		return StatFailed
+	case !fi.IsDir():
+		return NotDir
+	case !canEnter(path):
+		return PermissionDenied
+	}
+	return NotStale
+}
+
+// StaleFilter keeps only the bookmarks can not be cd to.
+type StaleFilter struct{}
+
+func NewStaleFilter() *StaleFilter {
+	return &StaleFilter{}
+}
+
+func (f *StaleFilter) Filter(b *Bookmark) bool {
+	return CheckStale(b.Path) != NotStale
 }
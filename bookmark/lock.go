@@ -0,0 +1,37 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import "os"
+
+// Lock takes the exclusive advisory lock of given db file, blocks until the
+// lock is acquired. Hold it around read-modify-write of the db file, and call
+// the returned func to release it.
+func Lock(file string) (func(), error) {
+	f, err := os.OpenFile(file+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
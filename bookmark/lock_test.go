@@ -0,0 +1,134 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// addLocked does a locked read-modify-write like `to save`.
+func addLocked(t *testing.T, file, name string) {
+	unlock, err := Lock(file)
+	if err != nil {
+		t.Errorf("Lock failed: %v", err)
+		return
+	}
+	defer unlock()
+
+	b, err := Load(file)
+	if err != nil {
+		t.Errorf("Load failed: %v", err)
+		return
+	}
+	if err := b.Add(name, "/"+name); err != nil {
+		t.Errorf("Add failed: %v", err)
+		return
+	}
+	if err := b.Save(file); err != nil {
+		t.Errorf("Save failed: %v", err)
+	}
+}
+
+func checkAllSaved(t *testing.T, file string, names []string) {
+	b, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	got := b.ListWithFilters(nil)
+	if len(got) != len(names) {
+		t.Fatalf("want %v bookmarks, got %v", len(names), len(got))
+	}
+	for _, name := range names {
+		if _, _, err := b.Match(name); err != nil {
+			t.Errorf("bookmark %v lost: %v", name, err)
+		}
+	}
+
+	// No temp file left.
+	matches, err := filepath.Glob(file + ".tmp*")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("want no temp file, got %v", matches)
+	}
+}
+
+func TestLockParallelGoroutines(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "db.json")
+
+	names := []string{}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("g%v", i)
+		names = append(names, name)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			addLocked(t, file, name)
+		}()
+	}
+	wg.Wait()
+
+	checkAllSaved(t, file, names)
+}
+
+func TestLockParallelProcesses(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "db.json")
+
+	names := []string{}
+	cmds := []*exec.Cmd{}
+	for i := 0; i < 10; i++ {
+		prefix := fmt.Sprintf("p%vx", i)
+		for j := 0; j < helperAdds; j++ {
+			names = append(names, fmt.Sprintf("%v%v", prefix, j))
+		}
+		cmd := exec.Command(os.Args[0], "-test.run=^TestLockHelperProcess$")
+		cmd.Env = append(os.Environ(),
+			"TO_LOCK_HELPER_FILE="+file,
+			"TO_LOCK_HELPER_PREFIX="+prefix)
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("helper process failed: %v", err)
+		}
+	}
+
+	checkAllSaved(t, file, names)
+}
+
+// helperAdds is the number of bookmarks added by each helper process.
+const helperAdds = 5
+
+// TestLockHelperProcess is run as sub process by TestLockParallelProcesses.
+func TestLockHelperProcess(t *testing.T) {
+	file := os.Getenv("TO_LOCK_HELPER_FILE")
+	if file == "" {
+		t.Skip("only run as helper process")
+	}
+	prefix := os.Getenv("TO_LOCK_HELPER_PREFIX")
+	for i := 0; i < helperAdds; i++ {
+		addLocked(t, file, fmt.Sprintf("%v%v", prefix, i))
+	}
+}
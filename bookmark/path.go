@@ -0,0 +1,125 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Tiers of path matching, lower is better.
+const (
+	tierLastComponent = iota
+	tierAnyComponent
+	tierNoMatch
+)
+
+// pathTier returns how the keywords match the path components. Keywords must
+// match components as substring in order, it is better if the last keyword
+// matches the last component.
+func pathTier(path string, keywords []string) int {
+	components := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	if len(keywords) == 0 {
+		return tierNoMatch
+	}
+
+	last := len(components) - 1
+	lastKeyword := len(keywords) - 1
+	if strings.Contains(components[last], keywords[lastKeyword]) &&
+		matchInOrder(components[:last], keywords[:lastKeyword]) {
+		return tierLastComponent
+	}
+	if matchInOrder(components, keywords) {
+		return tierAnyComponent
+	}
+	return tierNoMatch
+}
+
+// matchInOrder returns true if each keyword is substring of a component, and
+// the components are in the same order as keywords.
+func matchInOrder(components, keywords []string) bool {
+	i := 0
+	for _, c := range components {
+		if i == len(keywords) {
+			break
+		}
+		if strings.Contains(c, keywords[i]) {
+			i++
+		}
+	}
+	return i == len(keywords)
+}
+
+// MatchPath finds the bookmark by matching keywords against the bookmarked
+// paths like z, ranked by:
+// 1. the last keyword matches the last path component.
+// 2. the keywords match any path components in order.
+// Bookmarks in the same rank are ordered by frecency then shorter path,
+// return error if the top 2 tie.
+func (b *Bookmarks) MatchPath(keywords ...string) (*Bookmark, []Bookmark, error) {
+	query := strings.Join(keywords, " ")
+	folded := []string{}
+	for _, kw := range keywords {
+		folded = append(folded, b.fold(kw))
+	}
+
+	res := []Bookmark{}
+	tiers := map[string]int{}
+	for k, v := range b.data {
+		if tier := pathTier(b.fold(TryExpandPath(v.Path)), folded); tier != tierNoMatch {
+			res = append(res, v.bookmark(k))
+			tiers[k] = tier
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, nil, pathNotFoundErr(query)
+	}
+
+	if len(res) == 1 {
+		return &res[0], res, nil
+	}
+
+	compare := func(i, j int) int {
+		ni := res[i].Name
+		nj := res[j].Name
+		if tiers[ni] != tiers[nj] {
+			return tiers[ni] - tiers[nj]
+		}
+		si := b.data[ni].frecency()
+		sj := b.data[nj].frecency()
+		if si != sj {
+			if si > sj {
+				return -1
+			}
+			return 1
+		}
+		return len(res[i].Path) - len(res[j].Path)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if c := compare(i, j); c != 0 {
+			return c < 0
+		}
+		return res[i].Name < res[j].Name
+	})
+
+	if compare(0, 1) == 0 {
+		return nil, res, pathMoreThanOneMatchErr(query, res)
+	}
+
+	return &res[0], res, nil
+}
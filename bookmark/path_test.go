@@ -0,0 +1,135 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPathTier(t *testing.T) {
+	tests := []struct {
+		n        string
+		path     string
+		keywords []string
+		want     int
+	}{
+		{n: "last component", path: "/home/me/proj/api", keywords: []string{"api"}, want: tierLastComponent},
+		{n: "substring", path: "/home/me/proj/myapis", keywords: []string{"api"}, want: tierLastComponent},
+		{n: "any component", path: "/home/me/proj/api/src", keywords: []string{"api"}, want: tierAnyComponent},
+		{n: "in order last", path: "/home/me/proj/api", keywords: []string{"proj", "api"}, want: tierLastComponent},
+		{n: "in order any", path: "/home/me/proj/api/src", keywords: []string{"me", "api"}, want: tierAnyComponent},
+		{n: "wrong order", path: "/home/me/proj/api", keywords: []string{"api", "proj"}, want: tierNoMatch},
+		{n: "no match", path: "/home/me/proj/api", keywords: []string{"web"}, want: tierNoMatch},
+		{n: "no keywords", path: "/home/me/proj/api", keywords: nil, want: tierNoMatch},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			if got := pathTier(tc.path, tc.keywords); got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	ts := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	b := &Bookmarks{
+		data: map[string]*record{
+			"a": {Path: "/p/proj/api"},
+			"b": {Path: "/p/proj/api/src"},
+			"c": {Path: "/p/other/api"},
+			"d": {Path: "/p/web1", Visits: 1, LastVisit: ts},
+			"e": {Path: "/p/web2"},
+			"f": {Path: "/p/x/doc"},
+			"g": {Path: "/p/y/doc"},
+		},
+	}
+
+	tests := []struct {
+		n        string
+		keywords []string
+		want1    string
+		want2    []string
+		et       BookmarkErrType
+	}{
+		{n: "last component wins", keywords: []string{"proj", "api"}, want1: "a", want2: []string{"a", "b"}},
+		{n: "frecency wins", keywords: []string{"web"}, want1: "d", want2: []string{"d", "e"}},
+		{n: "shorter path wins", keywords: []string{"api"}, want1: "a", want2: []string{"a", "c", "b"}},
+		{n: "not found", keywords: []string{"zzz"}, et: PathNotFound},
+		{n: "more than 1", keywords: []string{"doc"}, want2: []string{"f", "g"}, et: MoreThanOneMatch},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			got1, got2, err := b.MatchPath(tc.keywords...)
+			if !IsErrType(err, tc.et) && !(err == nil && tc.et == NoErr) {
+				t.Fatalf("want err type %v, got %v", tc.et, err)
+			}
+			name := ""
+			if got1 != nil {
+				name = got1.Name
+			}
+			if diff := cmp.Diff(tc.want1, name); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+			names := []string{}
+			for _, bm := range got2 {
+				names = append(names, bm.Name)
+			}
+			if tc.want2 == nil {
+				tc.want2 = []string{}
+			}
+			if diff := cmp.Diff(tc.want2, names); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+}
+
+func TestMatchPathIgnoreCase(t *testing.T) {
+	b := &Bookmarks{
+		data: map[string]*record{
+			"a": {Path: "/p/Projects/API"},
+		},
+	}
+
+	if _, _, err := b.MatchPath("api"); !IsErrType(err, PathNotFound) {
+		t.Errorf("want case sensitive not found, got %v", err)
+	}
+
+	b.SetIgnoreCase(true)
+	got, _, err := b.MatchPath("proj", "Api")
+	if err != nil {
+		t.Fatalf("MatchPath failed: %v", err)
+	}
+	if got.Name != "a" {
+		t.Errorf("want a, got %v", got.Name)
+	}
+
+	got, _, err = b.Query("A", "api")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got.Name != "a" {
+		t.Errorf("want a, got %v", got.Name)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ProjectFiles are the names of project bookmark files, checked in this order
+// in each dir.
+var ProjectFiles = []string{".to.json", ".tobookmarks"}
+
+// FindProjectFile walks up from dir and returns the nearest project bookmark
+// file, empty if there is none.
+func FindProjectFile(dir string) string {
+	for {
+		for _, name := range ProjectFiles {
+			file := filepath.Join(dir, name)
+			if fi, err := os.Stat(file); err == nil && fi.Mode().IsRegular() {
+				return file
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadProject reads a project bookmark file, a json object of name to path or
+// to {"path", "tags", "note"}. Relative paths are resolved against the dir of
+// the file, portable paths are kept.
+func LoadProject(file string) (*Bookmarks, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project file: %w", err)
+	}
+	data := map[string]*record{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal project file %v: %w", file, err)
+	}
+	dir := filepath.Dir(file)
+	for name, r := range data {
+		if r == nil || r.Path == "" {
+			return nil, fmt.Errorf("bookmark %v in project file %v has no path", name, file)
+		}
+		if !filepath.IsAbs(r.Path) && !IsPortable(r.Path) {
+			r.Path = filepath.Join(dir, r.Path)
+		}
+	}
+	return &Bookmarks{data: data}, nil
+}
+
+// Merge returns bookmarks of b and local, local ones win if names conflict.
+// The records are shared, changes should be made to b or local.
+func (b *Bookmarks) Merge(local *Bookmarks) *Bookmarks {
+	data := map[string]*record{}
+	for k, v := range b.data {
+		data[k] = v
+	}
+	for k, v := range local.data {
+		data[k] = v
+	}
+	return &Bookmarks{data: data, ignoreCase: b.ignoreCase}
+}
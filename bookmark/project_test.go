@@ -0,0 +1,105 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindProjectFile(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"repo/a/b", "repo/other", "repo2/x"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+	}
+	for _, f := range []string{"repo/.to.json", "repo/a/.tobookmarks", "repo2/.to.json", "repo2/.tobookmarks"} {
+		if err := os.WriteFile(filepath.Join(root, f), []byte("{}"), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{dir: "repo/a/b", want: "repo/a/.tobookmarks"},
+		{dir: "repo/other", want: "repo/.to.json"},
+		{dir: "repo", want: "repo/.to.json"},
+		{dir: "repo2/x", want: "repo2/.to.json"},
+		{dir: ".", want: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.dir, func(t *testing.T) {
+			want := ""
+			if tc.want != "" {
+				want = filepath.Join(root, tc.want)
+			}
+			if got := FindProjectFile(filepath.Join(root, tc.dir)); got != want {
+				t.Errorf("want %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestLoadProject(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, ".to.json")
+	content := `{"api": "services/api", "web": {"path": "/abs/web", "tags": ["fe"], "note": "frontend"}}`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	local, err := LoadProject(file)
+	if err != nil {
+		t.Fatalf("LoadProject failed: %v", err)
+	}
+	want := []Bookmark{
+		{Name: "api", Path: filepath.Join(root, "services/api")},
+		{Name: "web", Path: "/abs/web", Tags: []string{"fe"}, Note: "frontend"},
+	}
+	if diff := cmp.Diff(want, local.ListWithFilters(nil)); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	global := NewBookMarkForTesting()
+	global.Add("api", "/global/api")
+	global.Add("docs", "/global/docs")
+	merged := global.Merge(local)
+	got, _, err := merged.Match("api")
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if got.Path != filepath.Join(root, "services/api") {
+		t.Errorf("want local api preferred, got %v", got.Path)
+	}
+	if _, err := merged.Get("docs"); err != nil {
+		t.Errorf("want global docs merged: %v", err)
+	}
+
+	for _, bad := range []string{`[]`, `{"api": ""}`} {
+		if err := os.WriteFile(file, []byte(bad), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		if _, err := LoadProject(file); err == nil {
+			t.Errorf("want error for %v", bad)
+		}
+	}
+}
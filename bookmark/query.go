@@ -0,0 +1,116 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Scores of a keyword matching a bookmark in Query.
+const (
+	scoreExactName     = 4
+	scoreNamePrefix    = 3
+	scoreTag           = 2
+	scorePathComponent = 1
+)
+
+// keywordScore returns the best score of keyword matching the bookmark, 0 if
+// not matched. Path components are compared after fold.
+func keywordScore(name string, r *record, keyword string, fold func(string) string) int {
+	switch {
+	case name == keyword:
+		return scoreExactName
+	case strings.HasPrefix(name, keyword):
+		return scoreNamePrefix
+	case slices.Contains(r.Tags, keyword):
+		return scoreTag
+	}
+	for _, c := range strings.Split(filepath.ToSlash(TryExpandPath(r.Path)), "/") {
+		if strings.Contains(fold(c), keyword) {
+			return scorePathComponent
+		}
+	}
+	return 0
+}
+
+// Query finds the bookmark matches all keywords, each keyword can match as
+// name prefix, tag or path component substring. Bookmarks are ranked by the
+// sum of how good each keyword matches, exact name > name prefix > tag > path
+// component, then frecency and shorter path, return error if the top 2 tie.
+func (b *Bookmarks) Query(keywords ...string) (*Bookmark, []Bookmark, error) {
+	query := strings.Join(keywords, " ")
+	if len(keywords) == 0 {
+		return nil, nil, queryNotFoundErr(query)
+	}
+
+	res := []Bookmark{}
+	scores := map[string]int{}
+	for k, v := range b.data {
+		total := 0
+		for _, kw := range keywords {
+			score := keywordScore(k, v, b.fold(kw), b.fold)
+			if score == 0 {
+				total = 0
+				break
+			}
+			total += score
+		}
+		if total > 0 {
+			res = append(res, v.bookmark(k))
+			scores[k] = total
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, nil, queryNotFoundErr(query)
+	}
+
+	if len(res) == 1 {
+		return &res[0], res, nil
+	}
+
+	compare := func(i, j int) int {
+		ni := res[i].Name
+		nj := res[j].Name
+		if scores[ni] != scores[nj] {
+			return scores[nj] - scores[ni]
+		}
+		si := b.data[ni].frecency()
+		sj := b.data[nj].frecency()
+		if si != sj {
+			if si > sj {
+				return -1
+			}
+			return 1
+		}
+		return len(res[i].Path) - len(res[j].Path)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if c := compare(i, j); c != 0 {
+			return c < 0
+		}
+		return res[i].Name < res[j].Name
+	})
+
+	if compare(0, 1) == 0 {
+		return nil, res, queryMoreThanOneMatchErr(query, res)
+	}
+
+	return &res[0], res, nil
+}
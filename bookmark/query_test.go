@@ -0,0 +1,82 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQuery(t *testing.T) {
+	ts := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	b := &Bookmarks{
+		data: map[string]*record{
+			"svcauth":    {Path: "/w/services/auth", Tags: []string{"backend", "prod"}},
+			"svcbilling": {Path: "/w/services/billing", Tags: []string{"backend"}},
+			"web":        {Path: "/w/frontend/web", Tags: []string{"prod"}},
+			"webstaging": {Path: "/w/staging/web", Visits: 1, LastVisit: ts},
+			"docs1":      {Path: "/w/a/docs"},
+			"docs2":      {Path: "/w/b/docs"},
+		},
+	}
+
+	tests := []struct {
+		n        string
+		keywords []string
+		want1    string
+		want2    []string
+		et       BookmarkErrType
+	}{
+		{n: "name prefix and tag", keywords: []string{"svc", "prod"}, want1: "svcauth", want2: []string{"svcauth"}},
+		{n: "tag and path", keywords: []string{"backend", "bill"}, want1: "svcbilling", want2: []string{"svcbilling"}},
+		{n: "exact name wins", keywords: []string{"web"}, want1: "web", want2: []string{"web", "webstaging"}},
+		{n: "name prefix beats path", keywords: []string{"prod", "w"}, want1: "web", want2: []string{"web", "svcauth"}},
+		{n: "frecency wins", keywords: []string{"staging", "web"}, want1: "webstaging", want2: []string{"webstaging"}},
+		{n: "all keywords must match", keywords: []string{"svc", "frontend"}, et: QueryNotFound},
+		{n: "no keywords", keywords: nil, et: QueryNotFound},
+		{n: "more than 1", keywords: []string{"docs"}, want2: []string{"docs1", "docs2"}, et: MoreThanOneMatch},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			got1, got2, err := b.Query(tc.keywords...)
+			if !IsErrType(err, tc.et) && !(err == nil && tc.et == NoErr) {
+				t.Fatalf("want err type %v, got %v", tc.et, err)
+			}
+			name := ""
+			if got1 != nil {
+				name = got1.Name
+			}
+			if diff := cmp.Diff(tc.want1, name); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+			names := []string{}
+			for _, bm := range got2 {
+				names = append(names, bm.Name)
+			}
+			if tc.want2 == nil {
+				tc.want2 = []string{}
+			}
+			if diff := cmp.Diff(tc.want2, names); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+// Store is the backend persisting bookmarks.
+type Store interface {
+	// Load reads all bookmarks.
+	Load() (*Bookmarks, error)
+	// Save replaces all stored bookmarks with given.
+	Save(b *Bookmarks) error
+	// Update loads bookmarks, calls fn and saves the change if fn returns nil,
+	// in a transaction serialized with other updates.
+	Update(fn func(b *Bookmarks) error) error
+	// Close releases the resources held by the store.
+	Close() error
+}
+
+// JSONStore stores all bookmarks in a JSON file, rewrites the whole file on
+// every save.
+type JSONStore struct {
+	file string
+}
+
+func NewJSONStore(file string) *JSONStore {
+	return &JSONStore{file}
+}
+
+func (s *JSONStore) Load() (*Bookmarks, error) {
+	return Load(s.file)
+}
+
+func (s *JSONStore) Save(b *Bookmarks) error {
+	return b.Save(s.file)
+}
+
+func (s *JSONStore) Update(fn func(b *Bookmarks) error) error {
+	unlock, err := Lock(s.file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	b, err := Load(s.file)
+	if err != nil {
+		return err
+	}
+	if err := fn(b); err != nil {
+		return err
+	}
+	return b.Save(s.file)
+}
+
+func (s *JSONStore) Close() error {
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var ignoreTimes = cmpopts.IgnoreFields(Bookmark{}, "Created", "Updated", "LastVisit")
+
+func testStores(t *testing.T) map[string]Store {
+	dir := t.TempDir()
+	bolt, err := OpenBoltStore(filepath.Join(dir, "db.bolt"))
+	if err != nil {
+		t.Fatalf("OpenBoltStore failed: %v", err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+
+	return map[string]Store{
+		"json": NewJSONStore(filepath.Join(dir, "db.json")),
+		"bolt": bolt,
+	}
+}
+
+func loadForTest(t *testing.T, s Store) []Bookmark {
+	b, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return b.ListWithFilters(nil)
+}
+
+func TestStoreUpdate(t *testing.T) {
+	for n, s := range testStores(t) {
+		t.Run(n, func(t *testing.T) {
+			if got := loadForTest(t, s); len(got) != 0 {
+				t.Errorf("want empty store, got %v", got)
+			}
+
+			err := s.Update(func(b *Bookmarks) error {
+				if err := b.Add("aaa", "bbb"); err != nil {
+					return err
+				}
+				return b.Add("aab", "ccc")
+			})
+			if err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+
+			err = s.Update(func(b *Bookmarks) error {
+				if err := b.Visit("aaa"); err != nil {
+					return err
+				}
+				return b.Delete("aab")
+			})
+			if err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+
+			b, err := s.Load()
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			want := []Bookmark{{Name: "aaa", Path: "bbb", Visits: 1}}
+			if diff := cmp.Diff(want, b.ListWithFilters(nil), ignoreTimes); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+}
+
+func TestStoreUpdateFailed(t *testing.T) {
+	for n, s := range testStores(t) {
+		t.Run(n, func(t *testing.T) {
+			err := s.Update(func(b *Bookmarks) error {
+				if err := b.Add("aaa", "bbb"); err != nil {
+					return err
+				}
+				return b.Add("aaa", "ccc")
+			})
+			if !errors.Is(err, ErrAlreadyExists) {
+				t.Fatalf("want ErrAlreadyExists, got %v", err)
+			}
+
+			if got := loadForTest(t, s); len(got) != 0 {
+				t.Errorf("want nothing saved, got %v", got)
+			}
+		})
+	}
+}
+
+func TestStoreSave(t *testing.T) {
+	for n, s := range testStores(t) {
+		t.Run(n, func(t *testing.T) {
+			err := s.Update(func(b *Bookmarks) error {
+				return b.Add("aaa", "bbb")
+			})
+			if err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+
+			b := NewBookMarkForTesting()
+			b.Add("ccc", "ddd")
+			if err := s.Save(b); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+
+			want := []Bookmark{{Name: "ccc", Path: "ddd"}}
+			if diff := cmp.Diff(want, loadForTest(t, s), ignoreTimes); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ResolveSubdir joins the slash separated rel onto dir. Each segment of rel
+// matches the sub dir with order.
+// 1. exact match
+// 2. shortest sub dir name with given as prefix, if more than 1, let choose
+// pick one, or return error if choose is nil. The error has the sub dirs as
+// candidates like Match.
+// Prefix matching ignores case if ignoreCase.
+func ResolveSubdir(dir, rel string, ignoreCase bool, choose Chooser) (string, error) {
+	for _, seg := range strings.Split(rel, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			dir = filepath.Join(dir, seg)
+			continue
+		}
+
+		next, err := matchSubdir(dir, seg, ignoreCase, choose)
+		if err != nil {
+			return "", err
+		}
+		dir = next
+	}
+	return dir, nil
+}
+
+func matchSubdir(dir, seg string, ignoreCase bool, choose Chooser) (string, error) {
+	exact := filepath.Join(dir, seg)
+	if fi, err := os.Stat(exact); err == nil && fi.IsDir() {
+		return exact, nil
+	}
+
+	res := []Bookmark{}
+	for _, name := range ListSubdirs(dir, seg, ignoreCase) {
+		res = append(res, Bookmark{Name: name, Path: filepath.Join(dir, name)})
+	}
+
+	if len(res) == 0 {
+		return "", subdirNotFoundErr(filepath.Join(dir, seg))
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return len(res[i].Name) < len(res[j].Name)
+	})
+
+	if len(res) > 1 && len(res[0].Name) == len(res[1].Name) {
+		if choose == nil {
+			return "", subdirMoreThanOneMatchErr(filepath.Join(dir, seg), res)
+		}
+		chosen, err := choose(res)
+		if err != nil {
+			return "", err
+		}
+		return chosen.Path, nil
+	}
+	return res[0].Path, nil
+}
+
+// ListSubdirs lists the names of sub dirs with given prefix, sorted. Hidden
+// dirs are listed only if prefix starts with ".".
+func ListSubdirs(dir, prefix string, ignoreCase bool) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	res := []string{}
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) &&
+			!(ignoreCase && strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix))) {
+			continue
+		}
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		// Follow symlinks to dir.
+		if fi, err := os.Stat(filepath.Join(dir, name)); err != nil || !fi.IsDir() {
+			continue
+		}
+		res = append(res, name)
+	}
+	return res
+}
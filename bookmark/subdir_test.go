@@ -0,0 +1,124 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func makeDirs(t *testing.T, dirs ...string) string {
+	root := t.TempDir()
+	for _, d := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+	}
+	return root
+}
+
+func TestResolveSubdir(t *testing.T) {
+	root := makeDirs(t,
+		"api/internal/handlers",
+		"api/internal/handlers2",
+		"api/interface",
+		"api/.hidden",
+		"app",
+		"web1",
+		"web2",
+		"Docs/Guide",
+	)
+	if err := os.WriteFile(filepath.Join(root, "apifile"), nil, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tests := []struct {
+		n          string
+		rel        string
+		ignoreCase bool
+		want       string
+		err        error
+	}{
+		{n: "exact", rel: "api/internal/handlers", want: "api/internal/handlers"},
+		{n: "trailing slash", rel: "api/internal/", want: "api/internal"},
+		{n: "prefix", rel: "api/inte/h", want: "api/internal/handlers"},
+		{n: "exact wins", rel: "app", want: "app"},
+		{n: "dot dot", rel: "api/../app", want: "app"},
+		{n: "hidden", rel: "api/.h", want: "api/.hidden"},
+		{n: "hidden not matched", rel: "api/h", err: ErrPrefixNotFound},
+		{n: "file not matched", rel: "apif", err: ErrPrefixNotFound},
+		{n: "not found", rel: "api/x", err: ErrPrefixNotFound},
+		{n: "more than 1", rel: "web", err: ErrAmbiguous},
+		{n: "case sensitive", rel: "docs/g", err: ErrPrefixNotFound},
+		{n: "ignore case", rel: "docs/g", ignoreCase: true, want: "Docs/Guide"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			got, err := ResolveSubdir(root, tc.rel, tc.ignoreCase, nil)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("want err %v, got %v", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+			if diff := cmp.Diff(filepath.Join(root, tc.want), got); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+}
+
+func TestResolveSubdirWithChooser(t *testing.T) {
+	root := makeDirs(t, "web1/src", "web2/src")
+
+	got, err := ResolveSubdir(root, "web/src", false, func(candidates []Bookmark) (*Bookmark, error) {
+		return &candidates[1], nil
+	})
+	if err != nil {
+		t.Fatalf("ResolveSubdir failed: %v", err)
+	}
+	if diff := cmp.Diff(filepath.Join(root, "web2", "src"), got); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	canceled := errors.New("canceled")
+	_, err = ResolveSubdir(root, "web/src", false, func(candidates []Bookmark) (*Bookmark, error) {
+		return nil, canceled
+	})
+	if err != canceled {
+		t.Errorf("want canceled, got %v", err)
+	}
+}
+
+func TestListSubdirs(t *testing.T) {
+	root := makeDirs(t, "aaa", "aab", "b", ".aac")
+
+	got := ListSubdirs(root, "a", false)
+	want := []string{"aaa", "aab"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	got = ListSubdirs(root, ".", false)
+	want = []string{".aac"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: `Read and edit the config file.`,
+	Long:  `Read and edit the config file $XDG_CONFIG_HOME/to/config.toml.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get key",
+	Short:             `Print the value of given key.`,
+	Long:              `Print the value of given key, the default if it is not set.`,
+	ValidArgsFunction: completeConfigKeys,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Fatalln("want exact 1 argument as key")
+		}
+		configGet(args[0])
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set key value",
+	Short:             `Set the value of given key.`,
+	Long:              `Set the value of given key, lists like match_order are comma separated. The value is validated before the file is written.`,
+	ValidArgsFunction: completeConfigKeys,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			log.Fatalln("want exact 2 arguments as key and value")
+		}
+		configSet(args[0], args[1])
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   `List all keys and their values.`,
+	Long:    `List all keys and their values.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		configList()
+	},
+}
+
+// completeConfigKeys completes the first argument with config keys.
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	res := []string{}
+	for _, k := range configKeys {
+		if strings.HasPrefix(k.name, toComplete) {
+			res = append(res, k.name+"\t"+k.usage)
+		}
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd)
+}
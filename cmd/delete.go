@@ -22,10 +22,11 @@ import (
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
-	Use:     "delete",
-	Aliases: []string{"rm", "del"},
-	Short:   `Delete given bookmark.`,
-	Long:    `Delete given bookmark.`,
+	Use:               "delete",
+	Aliases:           []string{"rm", "del"},
+	Short:             `Delete given bookmark.`,
+	Long:              `Delete given bookmark.`,
+	ValidArgsFunction: completeBookmarkNames,
 	Run: func(cmd *cobra.Command, args []string) {
 		ensureConfigFileDir()
 		if len(args) != 1 {
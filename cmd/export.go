@@ -0,0 +1,50 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+	"strings"
+
+	"github.com/chaopeng/to/importer"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormatFlag string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: `Export bookmarks to stdout.`,
+	Long: `Export bookmarks to stdout. json, csv and yaml can be imported with
+"to import --format", sh defines j_<name> aliases to cd.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		if len(args) != 0 {
+			log.Fatalln("want no argument")
+		}
+		exportBookmarks(exportFormatFlag, tagsFlag)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(&exportFormatFlag, "format", importer.FormatJSON, "one of "+strings.Join(importer.ExportFormats, ", "))
+	exportCmd.Flags().StringArrayVarP(&tagsFlag, "tag", "t", nil, "only export bookmarks with the tag, can be repeated")
+}
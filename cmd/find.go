@@ -21,20 +21,36 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	fuzzyFlag       bool
+	interactiveFlag bool
+)
+
 // findCmd represents the find command
 var findCmd = &cobra.Command{
-	Use:   "find",
-	Short: `Find the bookmarked dir keyword match to given word.`,
-	Long:  `Find the bookmarked dir keyword match to given word.`,
+	Use:               "find",
+	Short:             `Find the bookmarked dir keyword match to given word.`,
+	Long:              `Find the bookmarked dir keyword match to given word.`,
+	ValidArgsFunction: completeBookmarkNames,
 	Run: func(cmd *cobra.Command, args []string) {
 		ensureConfigFileDir()
-		if len(args) != 1 {
-			log.Fatalln("want exact 1 argument as bookmark name")
+		if len(args) < 1 {
+			log.Fatalln("want at least 1 argument as keyword")
 		}
-		fmt.Println(findMatchedDir(args[0]))
+		outputFlag = resolveOutput(outputFlag)
+		r1, dir := findMatchedDir(args, fuzzyFlag, interactiveFlag)
+		if outputFlag == "" || outputFlag == outputPlain {
+			fmt.Println(dir)
+			return
+		}
+		printOutput(outputFlag, newOutputBookmark(r1, dir))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(findCmd)
+
+	findCmd.Flags().BoolVar(&fuzzyFlag, "fuzzy", false, "match bookmark name as subsequence instead of prefix")
+	findCmd.Flags().StringVarP(&outputFlag, "output", "o", "", outputFlagUsage)
+	findCmd.Flags().BoolVarP(&interactiveFlag, "interactive", "i", false, "pick one on terminal if more than 1 matches, with fzf if installed, TO_PICKER=builtin to not use fzf")
 }
@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"strings"
 
@@ -26,16 +27,37 @@ import (
 
 // genjCmd represents the genj command
 var genjCmd = &cobra.Command{
-	Use:   "genj",
-	Short: "Gen the autocompletion script for the specified shell for J (Fish only)",
-	Long:  `Gen the autocompletion script for the specified shell for J (Fish only)`,
+	Use:   "genj shell [token]",
+	Short: "Gen the autocompletion candidates for the specified shell for J",
+	Long: `Gen the autocompletion candidates for the specified shell for J, used by scripts from init command.
+If the current token is given as "name/sub", gen the sub dirs of it.`,
+	ValidArgs: supportedShells,
 	Run: func(cmd *cobra.Command, args []string) {
 		ensureConfigFileDir()
-		if len(args) != 1 || args[0] != "fish" {
-			log.Fatalln("only support fish for now")
+		if len(args) != 1 && len(args) != 2 {
+			log.Fatalln("want shell and optional current token")
 		}
-		b := bookmark.ReadFromFile(dbFile)
-		fmt.Println(genFishAutoForJ(b))
+		shell := args[0]
+		gen, ok := genAutoForJ[shell]
+		if !ok {
+			log.Fatalf("shell %q is not supported, want one of %v\n", shell, supportedShells)
+		}
+		// Warnings would break the completion, project file is checked by find.
+		b := readBookmarks()
+		if project := readProjectBookmarks(io.Discard); project != nil {
+			b = b.Merge(project)
+		}
+		if len(args) == 2 && strings.Contains(args[1], "/") {
+			dirs := genSubdirsForJ(b, args[1])
+			if shell == "zsh" {
+				for i, d := range dirs {
+					dirs[i] = strings.ReplaceAll(d, ":", "\\:")
+				}
+			}
+			fmt.Println(strings.Join(dirs, "\n"))
+			return
+		}
+		fmt.Println(gen(b))
 	},
 }
 
@@ -43,8 +65,31 @@ func init() {
 	rootCmd.AddCommand(genjCmd)
 }
 
+var genAutoForJ = map[string]func(b *bookmark.Bookmarks) string{
+	"bash": genBashAutoForJ,
+	"zsh":  genZshAutoForJ,
+	"fish": genFishAutoForJ,
+}
+
 // genFishAutoForJ gen the list for `complete -f -c j -a {result}`
 func genFishAutoForJ(b *bookmark.Bookmarks) string {
+	return genBookmarkList(b, "\t")
+}
+
+// genZshAutoForJ gen the list for `_describe`
+func genZshAutoForJ(b *bookmark.Bookmarks) string {
+	return genBookmarkList(b, ":")
+}
+
+// genBashAutoForJ gen the list for `compgen -W`
+func genBashAutoForJ(b *bookmark.Bookmarks) string {
+	return genBookmarkList(b, "")
+}
+
+// genBookmarkList gen one bookmark per line, the name and description joined
+// by sep, description is omitted if sep is empty. The description is the note
+// if present, otherwise the path.
+func genBookmarkList(b *bookmark.Bookmarks, sep string) string {
 	var sb strings.Builder
 
 	list := b.ListWithFilters(nil)
@@ -53,9 +98,49 @@ func genFishAutoForJ(b *bookmark.Bookmarks) string {
 			sb.WriteString("\n")
 		}
 		sb.WriteString(b.Name)
-		sb.WriteString("\t")
-		sb.WriteString(dirShorten(b.Path, false))
+		if sep != "" {
+			sb.WriteString(sep)
+			if b.Note != "" {
+				sb.WriteString(b.Note)
+			} else {
+				sb.WriteString(dirShorten(b.Path, false))
+			}
+			if len(b.Tags) > 0 {
+				sb.WriteString(" ")
+				sb.WriteString(formatTags(b.Tags))
+			}
+		}
 	}
 
 	return sb.String()
 }
+
+// genSubdirsForJ gen the sub dirs for token like "name/sub/pre", as
+// "name/sub/prefix-matched/".
+func genSubdirsForJ(b *bookmark.Bookmarks, token string) []string {
+	name, rel, _ := strings.Cut(token, "/")
+	r, _, err := b.FuzzyMatch(name)
+	if err != nil {
+		return nil
+	}
+
+	parent, partial := "", rel
+	if i := strings.LastIndex(rel, "/"); i >= 0 {
+		parent, partial = rel[:i], rel[i+1:]
+	}
+	dir, err := bookmark.ExpandPath(r.Path)
+	if err != nil {
+		return nil
+	}
+	dir, err = bookmark.ResolveSubdir(dir, parent, cfg.IgnoreCase, nil)
+	if err != nil {
+		return nil
+	}
+
+	res := []string{}
+	prefix := strings.TrimSuffix(token, partial)
+	for _, d := range bookmark.ListSubdirs(dir, partial, cfg.IgnoreCase) {
+		res = append(res, prefix+d+"/")
+	}
+	return res
+}
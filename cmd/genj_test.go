@@ -15,22 +15,29 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/chaopeng/to/bookmark"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestGenFishAutoForJ(t *testing.T) {
 	b := bookmark.NewBookMarkForTesting()
 	b.Add("a", "111")
 	b.Add("b", "(home)/222")
+	b.Add("c", "333")
+	b.Tag("c", []string{"t1", "t2"}, nil)
+	b.Add("d", "444")
+	b.SetNote("d", "some note")
 
 	homeDir = "(home)"
 
 	got := genFishAutoForJ(b)
-	want := "a\t111\nb\t~/222"
+	want := "a\t111\nb\t~/222\nc\t333 [t1, t2]\nd\tsome note"
 	if d := cmp.Diff(want, got); d != "" {
 		t.Errorf("-want, +got:\n%v", d)
 	}
@@ -47,3 +54,65 @@ func TestGenFishAutoForJWithEmptyBookmark(t *testing.T) {
 		t.Errorf("-want, +got:\n%v", d)
 	}
 }
+
+func TestGenZshAutoForJ(t *testing.T) {
+	b := bookmark.NewBookMarkForTesting()
+	b.Add("a", "111")
+	b.Add("b", "(home)/222")
+
+	homeDir = "(home)"
+
+	got := genZshAutoForJ(b)
+	want := "a:111\nb:~/222"
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("-want, +got:\n%v", d)
+	}
+}
+
+func TestGenBashAutoForJ(t *testing.T) {
+	b := bookmark.NewBookMarkForTesting()
+	b.Add("a", "111")
+	b.Add("b", "(home)/222")
+
+	homeDir = "(home)"
+
+	got := genBashAutoForJ(b)
+	want := "a\nb"
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("-want, +got:\n%v", d)
+	}
+}
+
+func TestGenSubdirsForJ(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"api/internal/handlers", "api/interface", "app"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+	}
+
+	b := bookmark.NewBookMarkForTesting()
+	b.Add("proj", root)
+
+	tests := []struct {
+		n     string
+		token string
+		want  []string
+	}{
+		{n: "all sub dirs", token: "proj/", want: []string{"proj/api/", "proj/app/"}},
+		{n: "prefix", token: "pro/ap", want: []string{"pro/api/", "pro/app/"}},
+		{n: "nested", token: "proj/api/inter", want: []string{"proj/api/interface/", "proj/api/internal/"}},
+		{n: "nested prefix", token: "proj/api/internal/", want: []string{"proj/api/internal/handlers/"}},
+		{n: "bookmark not found", token: "x/", want: nil},
+		{n: "sub dir not found", token: "proj/x/", want: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			got := genSubdirsForJ(b, tc.token)
+			if d := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); d != "" {
+				t.Errorf("-want, +got:\n%v", d)
+			}
+		})
+	}
+}
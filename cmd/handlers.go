@@ -15,13 +15,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/chaopeng/to/bookmark"
+	"github.com/chaopeng/to/importer"
 
 	"github.com/fatih/color"
 )
@@ -31,11 +36,21 @@ var (
 	bold      = color.New(color.Bold)
 	blueBold  = color.New(color.FgBlue, color.Bold)
 	cyanBold  = color.New(color.FgCyan, color.Bold)
+	red       = color.New(color.FgRed)
 )
 
-func listWithFilters(prefix string, dir string, filters []bookmark.BookmarkFilter) {
-	b := bookmark.ReadFromFile(dbFile)
+func listWithFilters(prefix string, dir string, tags []string, filters []bookmark.BookmarkFilter, output string) {
+	b := readBookmarks()
 	res := b.ListWithFilters(filters)
+	sortBookmarks(res, cfg.Sort)
+	if output != "" {
+		l := []outputBookmark{}
+		for _, bm := range res {
+			l = append(l, newOutputBookmark(&bm, ""))
+		}
+		printOutputList(output, l)
+		return
+	}
 	bold.Printf("Found %v saved bookmarks", len(res))
 	if prefix != "" {
 		bold.Printf(" with prefix %q", prefix)
@@ -43,6 +58,9 @@ func listWithFilters(prefix string, dir string, filters []bookmark.BookmarkFilte
 	if dir != "" {
 		bold.Printf(" under dir %q", dirShorten(dir, false))
 	}
+	if len(tags) > 0 {
+		bold.Printf(" tagged %v", strings.Join(tags, ", "))
+	}
 	bold.Println()
 	fmt.Println(splitLine)
 	printBookmarks(res, func(b *bookmark.Bookmark) string {
@@ -51,11 +69,206 @@ func listWithFilters(prefix string, dir string, filters []bookmark.BookmarkFilte
 		sb.WriteString(strings.TrimPrefix(b.Name, prefix))
 		sb.WriteString(": ")
 		sb.WriteString(dirShorten(b.Path, true))
+		if len(b.Tags) > 0 {
+			sb.WriteString(" ")
+			sb.WriteString(blueBold.Sprint(formatTags(b.Tags)))
+		}
+		if b.Note != "" {
+			sb.WriteString(" # ")
+			sb.WriteString(b.Note)
+		}
+		if reason := bookmark.CheckStale(b.Path); reason != bookmark.NotStale {
+			sb.WriteString(red.Sprintf(" (%v)", reason))
+		}
 		sb.WriteString("\n")
 		return sb.String()
 	})
 }
 
+func doctor() {
+	b := readBookmarks()
+	res := b.ListWithFilters([]bookmark.BookmarkFilter{bookmark.NewStaleFilter()})
+	if len(res) == 0 {
+		bold.Println("All bookmarks are good")
+		return
+	}
+	bold.Printf("Found %v stale bookmarks\n", len(res))
+	fmt.Println(splitLine)
+	printBookmarks(res, func(b *bookmark.Bookmark) string {
+		return fmt.Sprintf("%v: %v %v\n", b.Name, dirShorten(b.Path, true),
+			red.Sprintf("(%v)", bookmark.CheckStale(b.Path)))
+	})
+}
+
+func prune(dryRun bool) {
+	var res []bookmark.Bookmark
+	err := updateBookmarks(func(b *bookmark.Bookmarks) error {
+		// Skip the bookmarks may be stale for transient errors.
+		for _, bm := range b.ListWithFilters([]bookmark.BookmarkFilter{bookmark.NewStaleFilter()}) {
+			if bookmark.CheckStale(bm.Path).Removable() {
+				res = append(res, bm)
+			}
+		}
+		if dryRun {
+			return errDryRun
+		}
+		for _, bm := range res {
+			if err := b.Delete(bm.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		log.Fatalf("Prune bookmarks failed: %v\n", err)
+	}
+
+	if dryRun {
+		bold.Printf("Would remove %v stale bookmarks\n", len(res))
+	} else {
+		bold.Printf("Removed %v stale bookmarks\n", len(res))
+	}
+	fmt.Println(splitLine)
+	printBookmarks(res, func(b *bookmark.Bookmark) string {
+		return fmt.Sprintf("%v: %v\n", b.Name, dirShorten(b.Path, true))
+	})
+}
+
+// importDirs merges the dirs in database file of other tool, prints the
+// imported, renamed and skipped dirs.
+func importDirs(from, file string, dryRun bool) {
+	r := openImportFile(file)
+	defer r.Close()
+	entries, err := importer.Parse(from, r)
+	if err != nil {
+		log.Fatalf("Failed to parse %v: %v\n", file, err)
+	}
+
+	// fasd also records files.
+	dirs := []importer.Entry{}
+	for _, e := range entries {
+		if bookmark.CheckStale(e.Path) != bookmark.NotDir {
+			e.Path = portablePath(e.Path)
+			dirs = append(dirs, e)
+		}
+	}
+
+	mergeImported(from, dryRun, func(b *bookmark.Bookmarks) (*importer.Report, error) {
+		return importer.Merge(b, dirs)
+	})
+}
+
+// importBookmarks merges the bookmarks exported by to in format, conflicts
+// are resolved with strategy.
+func importBookmarks(format string, strategy importer.Strategy, file string, dryRun bool) {
+	r := openImportFile(file)
+	defer r.Close()
+	bookmarks, err := importer.Decode(r, format)
+	if err != nil {
+		log.Fatalf("Failed to parse %v: %v\n", file, err)
+	}
+	for i, bm := range bookmarks {
+		validateBookmarkName(bm.Name)
+		validateTags(bm.Tags)
+		bookmarks[i].Path = portablePath(bm.Path)
+	}
+
+	from := file
+	if file == "-" {
+		from = "stdin"
+	}
+	mergeImported(from, dryRun, func(b *bookmark.Bookmarks) (*importer.Report, error) {
+		return importer.MergeBookmarks(b, bookmarks, strategy)
+	})
+}
+
+// openImportFile opens file to import, "-" for stdin.
+func openImportFile(file string) io.ReadCloser {
+	if file == "-" {
+		return io.NopCloser(os.Stdin)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		log.Fatalf("Failed to open %v: %v\n", file, err)
+	}
+	return f
+}
+
+// mergeImported runs merge in a store transaction and prints the report.
+func mergeImported(from string, dryRun bool, merge func(b *bookmark.Bookmarks) (*importer.Report, error)) {
+	var report *importer.Report
+	err := updateBookmarks(func(b *bookmark.Bookmarks) error {
+		var err error
+		report, err = merge(b)
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		log.Fatalf("Import failed: %v\n", err)
+	}
+
+	if dryRun {
+		bold.Printf("Would import %v dirs from %v\n", len(report.Added), from)
+	} else {
+		bold.Printf("Imported %v dirs from %v\n", len(report.Added), from)
+	}
+	fmt.Println(splitLine)
+	printBookmarks(report.Added, func(b *bookmark.Bookmark) string {
+		return fmt.Sprintf("%v: %v\n", b.Name, dirShorten(b.Path, true))
+	})
+
+	if len(report.Overwritten) > 0 {
+		bold.Printf("Overwrote %v bookmarks\n", len(report.Overwritten))
+		fmt.Println(splitLine)
+		printBookmarks(report.Overwritten, func(b *bookmark.Bookmark) string {
+			return fmt.Sprintf("%v: %v\n", b.Name, dirShorten(b.Path, true))
+		})
+	}
+
+	if len(report.Renamed) > 0 {
+		bold.Printf("Renamed %v dirs as the names are taken\n", len(report.Renamed))
+		fmt.Println(splitLine)
+		for _, r := range report.Renamed {
+			fmt.Printf("%v -> %v: %v\n", r.Wanted, r.Name, dirShorten(r.Path, true))
+		}
+		fmt.Println()
+	}
+
+	if len(report.Skipped) > 0 {
+		bold.Printf("Skipped %v dirs conflicting with existing bookmarks\n", len(report.Skipped))
+		fmt.Println(splitLine)
+		for _, s := range report.Skipped {
+			fmt.Printf("%v: %v\n", dirShorten(s.Path, true), s.Existing)
+		}
+		fmt.Println()
+	}
+}
+
+// exportBookmarks writes bookmarks with all given tags to stdout in format.
+func exportBookmarks(format string, tags []string) {
+	validateTags(tags)
+	b := readBookmarks()
+	filters := []bookmark.BookmarkFilter{}
+	if len(tags) > 0 {
+		filters = append(filters, bookmark.NewTagFilter(tags...))
+	}
+	if err := importer.Export(os.Stdout, format, b.ListWithFilters(filters)); err != nil {
+		log.Fatalf("Export failed: %v\n", err)
+	}
+}
+
+// errDryRun aborts the update transaction without saving.
+var errDryRun = errors.New("dry run")
+
+// errNoChange aborts the update transaction as nothing is changed, so the
+// write is skipped.
+var errNoChange = errors.New("no change")
+
 func printBookmarks(l []bookmark.Bookmark, formatter func(b *bookmark.Bookmark) string) {
 	sb := strings.Builder{}
 	for _, b := range l {
@@ -64,42 +277,245 @@ func printBookmarks(l []bookmark.Bookmark, formatter func(b *bookmark.Bookmark)
 	fmt.Println(sb.String())
 }
 
-func save(name string) {
+func save(name string, tags []string) {
 	validateBookmarkName(name)
+	validateTags(tags)
 	curr, err := os.Getwd()
 	if err != nil {
 		log.Fatalf("pwd failed: %v\n", err)
 	}
-	b := bookmark.ReadFromFile(dbFile)
-	if err := b.Add(name, curr); err != nil {
+	curr = portablePath(followSymlinks(curr))
+	err = updateBookmarks(func(b *bookmark.Bookmarks) error {
+		if err := b.Add(name, curr); err != nil {
+			return err
+		}
+		_, err := b.Tag(name, tags, nil)
+		return err
+	})
+	if err != nil {
 		log.Fatalf("Add bookmark failed: %v\n", err)
 	}
-	b.SaveToFile(dbFile)
 }
 
 func delete(name string) {
 	validateBookmarkName(name)
-	b := bookmark.ReadFromFile(dbFile)
-	if err := b.Delete(name); err != nil {
+	err := updateBookmarks(func(b *bookmark.Bookmarks) error {
+		return b.Delete(name)
+	})
+	if err != nil {
 		log.Fatalf("Delete bookmark failed: %v\n", err)
 	}
-	b.SaveToFile(dbFile)
 }
 
-func findMatchedDir(name string) string {
+// tag applies changes like "+foo" or "foo" to add and "-bar" to remove tags,
+// and prints the tags after change.
+func tag(name string, changes []string) {
 	validateBookmarkName(name)
-	b := bookmark.ReadFromFile(dbFile)
-	r1, _, err := b.Match(name)
+	add, remove := parseTagChanges(changes)
+	validateTags(add)
+	validateTags(remove)
+
+	// Only print the tags if no change.
+	if len(add) == 0 && len(remove) == 0 {
+		r, err := readBookmarks().Get(name)
+		if err != nil {
+			log.Fatalf("Tag bookmark failed: %v\n", err)
+		}
+		fmt.Printf("%v: %v\n", name, formatTags(r.Tags))
+		return
+	}
+
+	var tags []string
+	err := updateBookmarks(func(b *bookmark.Bookmarks) error {
+		changed, err := b.Tag(name, add, remove)
+		if err != nil {
+			return err
+		}
+		r, err := b.Get(name)
+		if err != nil {
+			return err
+		}
+		tags = r.Tags
+		if !changed {
+			return errNoChange
+		}
+		return nil
+	})
+	if err != nil && err != errNoChange {
+		log.Fatalf("Tag bookmark failed: %v\n", err)
+	}
+	fmt.Printf("%v: %v\n", name, formatTags(tags))
+}
+
+// parseTagChanges splits changes to tags to add and remove.
+func parseTagChanges(changes []string) (add, remove []string) {
+	add = []string{}
+	remove = []string{}
+	for _, c := range changes {
+		switch {
+		case strings.HasPrefix(c, "+"):
+			add = append(add, c[1:])
+		case strings.HasPrefix(c, "-"):
+			remove = append(remove, c[1:])
+		default:
+			add = append(add, c)
+		}
+	}
+	return add, remove
+}
+
+// note sets the note of bookmark, prints the note if nil given.
+func note(name string, text *string) {
+	validateBookmarkName(name)
+	if text == nil {
+		b := readBookmarks()
+		r, err := b.Get(name)
+		if err != nil {
+			log.Fatalf("%v\n", err)
+		}
+		fmt.Println(r.Note)
+		return
+	}
+
+	err := updateBookmarks(func(b *bookmark.Bookmarks) error {
+		return b.SetNote(name, *text)
+	})
+	if err != nil {
+		log.Fatalf("Set note failed: %v\n", err)
+	}
+}
+
+func show(name string, output string) {
+	validateBookmarkName(name)
+	b := readBookmarks()
+	r, err := b.Get(name)
 	if err != nil {
 		log.Fatalf("%v\n", err)
 	}
-	return r1.Path
+	if output != "" {
+		printOutput(output, newOutputBookmark(r, ""))
+		return
+	}
+
+	field := func(k string, v any) {
+		fmt.Printf("%v %v\n", bold.Sprintf("%-11v", k+":"), v)
+	}
+	blueBold.Println(r.Name)
+	field("path", dirShorten(r.Path, true))
+	if reason := bookmark.CheckStale(r.Path); reason != bookmark.NotStale {
+		field("status", red.Sprint(reason))
+	}
+	field("note", r.Note)
+	field("tags", formatTags(r.Tags))
+	field("created", formatTime(r.Created))
+	field("updated", formatTime(r.Updated))
+	field("visits", r.Visits)
+	field("last visit", formatTime(r.LastVisit))
+}
+
+// formatTime formats time in local time zone, "-" if zero.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func rename(old, new string) {
+	validateBookmarkName(old)
+	validateBookmarkName(new)
+	err := updateBookmarks(func(b *bookmark.Bookmarks) error {
+		return b.Rename(old, new)
+	})
+	if err != nil {
+		log.Fatalf("Rename bookmark failed: %v\n", err)
+	}
+}
+
+func update(name, dir string) {
+	validateBookmarkName(name)
+	var err error
+	if dir == "" {
+		dir, err = os.Getwd()
+		if err != nil {
+			log.Fatalf("pwd failed: %v\n", err)
+		}
+	}
+	// Portable paths like "$WORKSPACE/api" are stored as given.
+	if bookmark.IsPortable(dir) {
+		dir = filepath.Clean(dir)
+	} else if dir, err = filepath.Abs(dir); err != nil {
+		log.Fatalf("Failed to get absolute path: %v\n", err)
+	}
+	expanded, err := bookmark.ExpandPath(dir)
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	if fi, err := os.Stat(expanded); err != nil || !fi.IsDir() {
+		log.Fatalf("Given path %v is not a dir\n", dir)
+	}
+	if !bookmark.IsPortable(dir) {
+		dir = portablePath(followSymlinks(dir))
+	}
+	err = updateBookmarks(func(b *bookmark.Bookmarks) error {
+		return b.Update(name, dir)
+	})
+	if err != nil {
+		log.Fatalf("Update bookmark failed: %v\n", err)
+	}
+}
+
+// portablePath stores path under $HOME as "~/..." or under one of path_vars
+// as "$VAR/...".
+func portablePath(path string) string {
+	return bookmark.PortablePath(path, cfg.PathVars)
+}
+
+// followSymlinks resolves the symlinks in dir if follow_symlinks is set.
+func followSymlinks(dir string) string {
+	if !cfg.FollowSymlinks {
+		return dir
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		log.Fatalf("Failed to resolve symlinks: %v\n", err)
+	}
+	return resolved
+}
+
+// formatTags formats tags as "[a, b]".
+func formatTags(tags []string) string {
+	return "[" + strings.Join(tags, ", ") + "]"
 }
 
 var bookmarkRE = regexp.MustCompile("^[a-z][a-z0-9]*$")
 
 func validateBookmarkName(name string) {
+	if err := checkBookmarkName(name); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+}
+
+func checkBookmarkName(name string) error {
 	if !bookmarkRE.MatchString(name) {
-		log.Fatalf("Given bookmark name %v is invalid\n", name)
+		return fmt.Errorf("Given bookmark name %v is invalid", name)
+	}
+	return nil
+}
+
+var tagRE = regexp.MustCompile("^[a-z0-9][a-z0-9_-]*$")
+
+func validateTags(tags []string) {
+	if err := checkTags(tags); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+}
+
+func checkTags(tags []string) error {
+	for _, t := range tags {
+		if !tagRE.MatchString(t) {
+			return fmt.Errorf("Given tag %v is invalid", t)
+		}
 	}
+	return nil
 }
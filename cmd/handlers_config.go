@@ -0,0 +1,446 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chaopeng/to/bookmark"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Strategies to match a single keyword, tried in the order of match_order.
+const (
+	matchPrefix = "prefix"
+	matchFuzzy  = "fuzzy"
+	matchPath   = "path"
+)
+
+var matchStrategies = []string{matchPrefix, matchFuzzy, matchPath}
+
+// Color modes, auto colors if stdout is a terminal.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+var colorModes = []string{colorAuto, colorAlways, colorNever}
+
+// Sort orders of list.
+const (
+	sortName     = "name"
+	sortPath     = "path"
+	sortFrecency = "frecency"
+	sortRecent   = "recent"
+	sortCreated  = "created"
+)
+
+var sortOrders = []string{sortName, sortPath, sortFrecency, sortRecent, sortCreated}
+
+// config is the config file, $XDG_CONFIG_HOME/to/config.toml.
+type config struct {
+	// DB is the path of db file.
+	DB string `toml:"db"`
+	// Store is the store backend, one of stores.
+	Store string `toml:"store"`
+	// MatchOrder is the strategies to match a single keyword.
+	MatchOrder []string `toml:"match_order"`
+	// IgnoreCase matches keywords and paths case insensitively.
+	IgnoreCase bool `toml:"ignore_case"`
+	// FollowSymlinks saves the resolved path instead of the symlink.
+	FollowSymlinks bool `toml:"follow_symlinks"`
+	// Color is one of colorModes.
+	Color string `toml:"color"`
+	// AbbreviateHome shows $HOME as "~".
+	AbbreviateHome bool `toml:"abbreviate_home"`
+	// Sort is the order of list, one of sortOrders.
+	Sort string `toml:"sort"`
+	// Output is the default of --output flag, empty for human readable.
+	Output string `toml:"output"`
+	// Fallthrough is the profiles find searches if nothing found in current
+	// profile.
+	Fallthrough []string `toml:"fallthrough"`
+	// PathVars is the env vars saved paths are stored relative to, eg.
+	// "$WORKSPACE/api".
+	PathVars []string `toml:"path_vars"`
+}
+
+// cfg is the loaded config, loaded in ensureConfigFileDir.
+var cfg = defaultConfig()
+
+func defaultConfig() *config {
+	return &config{
+		MatchOrder:     []string{matchPrefix, matchPath},
+		Store:          storeJSON,
+		Color:          colorAuto,
+		AbbreviateHome: true,
+		Sort:           sortName,
+	}
+}
+
+// loadConfig reads the config file over the defaults, returns the defaults if
+// the file does not exist.
+func loadConfig(file string) (*config, error) {
+	content, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return defaultConfig(), nil
+	}
+	var c *config
+	if err == nil {
+		c, err = decodeConfig(string(content))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %v: %w", file, err)
+	}
+	return c, nil
+}
+
+// decodeConfig decodes the content over the defaults and validates it, unknown
+// keys are rejected so typos are not ignored silently.
+func decodeConfig(content string) (*config, error) {
+	c := defaultConfig()
+	md, err := toml.Decode(content, c)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown config key %q", undecoded[0].String())
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *config) validate() error {
+	if len(c.MatchOrder) == 0 {
+		return errors.New("match_order is empty")
+	}
+	for _, s := range c.MatchOrder {
+		if err := checkOneOf("match_order", s, matchStrategies); err != nil {
+			return err
+		}
+	}
+	if err := checkOneOf("store", c.Store, stores); err != nil {
+		return err
+	}
+	if err := checkOneOf("color", c.Color, colorModes); err != nil {
+		return err
+	}
+	if err := checkOneOf("sort", c.Sort, sortOrders); err != nil {
+		return err
+	}
+	if c.Output != "" {
+		if err := checkOneOf("output", c.Output, outputs); err != nil {
+			return err
+		}
+	}
+	for _, p := range c.Fallthrough {
+		if err := checkProfileName(p); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.PathVars {
+		if !envVarRE.MatchString(v) {
+			return fmt.Errorf("invalid env var %q in path_vars", v)
+		}
+	}
+	return nil
+}
+
+var envVarRE = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
+
+func checkOneOf(key, value string, allowed []string) error {
+	if !slices.Contains(allowed, value) {
+		return fmt.Errorf("unknown %v %q, want one of %v", key, value, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// configKey is a key can be read and written by the config command.
+type configKey struct {
+	name  string
+	usage string
+	get   func(c *config) string
+	// parse converts the value from command line to the value in toml.
+	parse func(s string) (any, error)
+}
+
+func parseString(s string) (any, error) { return s, nil }
+
+func parseBool(s string) (any, error) { return strconv.ParseBool(s) }
+
+// parseList splits a comma separated list.
+func parseList(s string) (any, error) {
+	l := []string{}
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			l = append(l, e)
+		}
+	}
+	return l, nil
+}
+
+var configKeys = []configKey{
+	{
+		name:  "db",
+		usage: "path of the database file",
+		get:   func(c *config) string { return c.DB },
+		parse: parseString,
+	},
+	{
+		name:  "store",
+		usage: "store backend: " + strings.Join(stores, ", "),
+		get:   func(c *config) string { return c.Store },
+		parse: parseString,
+	},
+	{
+		name:  "match_order",
+		usage: "comma separated strategies to match a keyword: " + strings.Join(matchStrategies, ", "),
+		get:   func(c *config) string { return strings.Join(c.MatchOrder, ",") },
+		parse: parseList,
+	},
+	{
+		name:  "ignore_case",
+		usage: "match keywords and paths case insensitively",
+		get:   func(c *config) string { return strconv.FormatBool(c.IgnoreCase) },
+		parse: parseBool,
+	},
+	{
+		name:  "follow_symlinks",
+		usage: "save the resolved path of symlinks",
+		get:   func(c *config) string { return strconv.FormatBool(c.FollowSymlinks) },
+		parse: parseBool,
+	},
+	{
+		name:  "color",
+		usage: "color output: " + strings.Join(colorModes, ", "),
+		get:   func(c *config) string { return c.Color },
+		parse: parseString,
+	},
+	{
+		name:  "abbreviate_home",
+		usage: "show home dir as ~",
+		get:   func(c *config) string { return strconv.FormatBool(c.AbbreviateHome) },
+		parse: parseBool,
+	},
+	{
+		name:  "sort",
+		usage: "order of list: " + strings.Join(sortOrders, ", "),
+		get:   func(c *config) string { return c.Sort },
+		parse: parseString,
+	},
+	{
+		name:  "output",
+		usage: "default output of list, find and show: " + strings.Join(outputs, ", ") + " or empty",
+		get:   func(c *config) string { return c.Output },
+		parse: parseString,
+	},
+	{
+		name:  "fallthrough",
+		usage: "comma separated profiles find searches if nothing found in current profile",
+		get:   func(c *config) string { return strings.Join(c.Fallthrough, ",") },
+		parse: parseList,
+	},
+	{
+		name:  "path_vars",
+		usage: "comma separated env vars saved paths are stored relative to",
+		get:   func(c *config) string { return strings.Join(c.PathVars, ",") },
+		parse: parseList,
+	},
+}
+
+func findConfigKey(name string) (*configKey, error) {
+	for i := range configKeys {
+		if configKeys[i].name == name {
+			return &configKeys[i], nil
+		}
+	}
+	names := []string{}
+	for _, k := range configKeys {
+		names = append(names, k.name)
+	}
+	return nil, fmt.Errorf("unknown config key %q, want one of %v", name, strings.Join(names, ", "))
+}
+
+// setConfigValue sets key to value in the config file. Only the line of key
+// is changed, so comments and the other keys are kept, and it is appended if
+// key is not in file. The file is validated before written, and replaced
+// atomically.
+func setConfigValue(file, key, value string) error {
+	k, err := findConfigKey(key)
+	if err != nil {
+		return err
+	}
+	v, err := k.parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid value %q of %v: %w", value, key, err)
+	}
+	buf := &bytes.Buffer{}
+	if err := toml.NewEncoder(buf).Encode(map[string]any{key: v}); err != nil {
+		return err
+	}
+	line := strings.TrimSuffix(buf.String(), "\n")
+
+	content, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read config %v: %w", file, err)
+	}
+	updated, err := replaceConfigLine(string(content), key, line)
+	if err != nil {
+		return fmt.Errorf("failed to edit config %v: %w", file, err)
+	}
+	if _, err := decodeConfig(updated); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(updated); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// CreateTemp creates file with 0600, keep the mode of existing file.
+	mode := fs.FileMode(0644)
+	if fi, err := os.Stat(file); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// replaceConfigLine replaces the line setting key in content with line, keeps
+// the comment after the value. The line is appended if key is not set. Values
+// span multiple lines are not supported, user has to edit them by hand.
+func replaceConfigLine(content, key, line string) (string, error) {
+	re := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(key) + `\s*=`)
+	lines := strings.Split(content, "\n")
+	for i, l := range lines {
+		if !re.MatchString(l) {
+			continue
+		}
+		value, comment := splitComment(l)
+		if _, err := toml.Decode(value, &map[string]any{}); err != nil {
+			return "", fmt.Errorf("can not edit %v in place, edit it by hand: %w", key, err)
+		}
+		if comment != "" {
+			line += " " + comment
+		}
+		lines[i] = line
+		return strings.Join(lines, "\n"), nil
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + line + "\n", nil
+}
+
+// splitComment splits the toml line at the "#" starting the comment, "#" in
+// strings are skipped.
+func splitComment(line string) (string, string) {
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return strings.TrimRight(line[:i], " \t"), line[i:]
+		}
+	}
+	return line, ""
+}
+
+// sortBookmarks sorts l in place by one of sortOrders, ties are sorted by name.
+func sortBookmarks(l []bookmark.Bookmark, by string) {
+	less := map[string]func(a, b *bookmark.Bookmark) bool{
+		sortPath: func(a, b *bookmark.Bookmark) bool {
+			return bookmark.TryExpandPath(a.Path) < bookmark.TryExpandPath(b.Path)
+		},
+		sortFrecency: func(a, b *bookmark.Bookmark) bool { return a.Frecency() > b.Frecency() },
+		sortRecent:   func(a, b *bookmark.Bookmark) bool { return a.LastVisit.After(b.LastVisit) },
+		sortCreated:  func(a, b *bookmark.Bookmark) bool { return a.Created.After(b.Created) },
+	}[by]
+	if less == nil {
+		return
+	}
+	sort.SliceStable(l, func(i, j int) bool {
+		return less(&l[i], &l[j])
+	})
+}
+
+// readConfig reads the config file, crash if error.
+func readConfig() *config {
+	c, err := loadConfig(configFilePath(os.Getenv))
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	return c
+}
+
+func configGet(key string) {
+	k, err := findConfigKey(key)
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	fmt.Println(k.get(readConfig()))
+}
+
+func configSet(key, value string) {
+	if err := setConfigValue(configFilePath(os.Getenv), key, value); err != nil {
+		log.Fatalf("Set config failed: %v\n", err)
+	}
+}
+
+func configList() {
+	c := readConfig()
+	sb := strings.Builder{}
+	for _, k := range configKeys {
+		fmt.Fprintf(&sb, "%v = %v\n", k.name, k.get(c))
+	}
+	fmt.Print(sb.String())
+}
@@ -0,0 +1,255 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaopeng/to/bookmark"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		n       string
+		content string
+		want    *config
+		wantErr bool
+	}{
+		{
+			n:    "defaults",
+			want: defaultConfig(),
+		},
+		{
+			n: "overlay",
+			content: `match_order = ["fuzzy"]
+ignore_case = true
+abbreviate_home = false
+sort = "frecency"`,
+			want: &config{
+				MatchOrder:     []string{matchFuzzy},
+				Store:          storeJSON,
+				IgnoreCase:     true,
+				Color:          colorAuto,
+				AbbreviateHome: false,
+				Sort:           sortFrecency,
+			},
+		},
+		{n: "bad toml", content: "sort = ", wantErr: true},
+		{n: "bad sort", content: `sort = "size"`, wantErr: true},
+		{n: "bad color", content: `color = "red"`, wantErr: true},
+		{n: "bad store", content: `store = "sqlite"`, wantErr: true},
+		{n: "bad match order", content: `match_order = ["regex"]`, wantErr: true},
+		{n: "empty match order", content: `match_order = []`, wantErr: true},
+		{n: "bad output", content: `output = "xml"`, wantErr: true},
+		{n: "unknown key", content: `ignorecase = true`, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			root := t.TempDir()
+			if tc.content != "" {
+				writeFile(t, root, "config.toml", tc.content)
+			}
+			got, err := loadConfig(filepath.Join(root, "config.toml"))
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadConfig failed: %v", err)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("-want, +got:\n%v", d)
+			}
+		})
+	}
+}
+
+func TestSetConfigValue(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "to", "config.toml")
+
+	for _, kv := range [][2]string{
+		{"sort", "recent"},
+		{"match_order", "path, fuzzy"},
+		{"ignore_case", "true"},
+	} {
+		if err := setConfigValue(file, kv[0], kv[1]); err != nil {
+			t.Fatalf("setConfigValue(%v, %v) failed: %v", kv[0], kv[1], err)
+		}
+	}
+
+	for _, kv := range [][2]string{
+		{"sort", "size"},
+		{"ignore_case", "yes please"},
+		{"colour", "never"},
+	} {
+		if err := setConfigValue(file, kv[0], kv[1]); err == nil {
+			t.Errorf("want error for %v = %v", kv[0], kv[1])
+		}
+	}
+
+	got, err := loadConfig(file)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	want := defaultConfig()
+	want.Sort = sortRecent
+	want.MatchOrder = []string{matchPath, matchFuzzy}
+	want.IgnoreCase = true
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("-want, +got:\n%v", d)
+	}
+
+	if fi, err := os.Stat(file); err != nil || fi.Mode().Perm() != 0644 {
+		t.Errorf("want new file with mode 0644, got %v %v", fi, err)
+	}
+	if err := os.Chmod(file, 0640); err != nil {
+		t.Fatalf("Chmod failed: %v", err)
+	}
+	if err := setConfigValue(file, "ignore_case", "true"); err != nil {
+		t.Fatalf("setConfigValue failed: %v", err)
+	}
+	if fi, err := os.Stat(file); err != nil || fi.Mode().Perm() != 0640 {
+		t.Errorf("want mode 0640 kept, got %v %v", fi, err)
+	}
+
+	// Only the keys set are written.
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	want2 := "sort = \"recent\"\nmatch_order = [\"path\", \"fuzzy\"]\nignore_case = true\n"
+	if d := cmp.Diff(want2, string(content)); d != "" {
+		t.Errorf("-want, +got:\n%v", d)
+	}
+}
+
+func TestSetConfigValueKeepsComments(t *testing.T) {
+	tests := []struct {
+		n       string
+		content string
+		key     string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			n:       "replace",
+			content: "# my comment\nsort = \"name\" # keep\n\ncolor = \"never\"\n",
+			key:     "sort",
+			value:   "frecency",
+			want:    "# my comment\nsort = \"frecency\" # keep\n\ncolor = \"never\"\n",
+		},
+		{
+			n:       "hash in string",
+			content: "db = \"~/a#b\" # old db\n",
+			key:     "db",
+			value:   "~/c",
+			want:    "db = \"~/c\" # old db\n",
+		},
+		{
+			n:       "append",
+			content: "# my comment\ncolor = \"never\"",
+			key:     "sort",
+			value:   "recent",
+			want:    "# my comment\ncolor = \"never\"\nsort = \"recent\"\n",
+		},
+		{
+			n:       "multiple lines",
+			content: "match_order = [\n  \"path\",\n]\n",
+			key:     "match_order",
+			value:   "fuzzy",
+			wantErr: true,
+		},
+		{
+			n:       "unknown key in file",
+			content: "ignorecase = true\n",
+			key:     "sort",
+			value:   "recent",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(file, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			err := setConfigValue(file, tc.key, tc.value)
+			content, readErr := os.ReadFile(file)
+			if readErr != nil {
+				t.Fatalf("ReadFile failed: %v", readErr)
+			}
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("want error")
+				}
+				if string(content) != tc.content {
+					t.Errorf("want file unchanged, got %q", content)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setConfigValue failed: %v", err)
+			}
+			if d := cmp.Diff(tc.want, string(content)); d != "" {
+				t.Errorf("-want, +got:\n%v", d)
+			}
+		})
+	}
+}
+
+func TestSortBookmarks(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	l := []bookmark.Bookmark{
+		{Name: "a", Path: "/c", Created: day(1), LastVisit: day(3)},
+		{Name: "b", Path: "/a", Created: day(3), LastVisit: day(1)},
+		{Name: "c", Path: "/b", Created: day(2), LastVisit: day(2)},
+	}
+
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{by: sortName, want: []string{"a", "b", "c"}},
+		{by: sortPath, want: []string{"b", "c", "a"}},
+		{by: sortRecent, want: []string{"a", "c", "b"}},
+		{by: sortCreated, want: []string{"b", "c", "a"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.by, func(t *testing.T) {
+			got := append([]bookmark.Bookmark{}, l...)
+			sortBookmarks(got, tc.by)
+			names := []string{}
+			for _, b := range got {
+				names = append(names, b.Name)
+			}
+			if d := cmp.Diff(tc.want, names); d != "" {
+				t.Errorf("-want, +got:\n%v", d)
+			}
+		})
+	}
+}
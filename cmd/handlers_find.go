@@ -0,0 +1,300 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/chaopeng/to/bookmark"
+	"github.com/chaopeng/to/tui"
+)
+
+// findMatchedDir finds the bookmark and dir of given keywords and records the
+// visit, see findDir. If interactive, ambiguous matches are picked by user.
+func findMatchedDir(keywords []string, fuzzy bool, interactive bool) (*bookmark.Bookmark, string) {
+	var choose bookmark.Chooser
+	if interactive {
+		choose = pickCandidate
+	}
+	r1, dir, err := findDir(keywords, matchOrder(fuzzy), choose, readProjectBookmarks(os.Stderr))
+	var e *bookmark.Err
+	if errors.Is(err, bookmark.ErrAmbiguous) && errors.As(err, &e) {
+		log.Fatalf("%v, did you mean %v?\n", err, didYouMean(e.Candidates()))
+	}
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	return r1, dir
+}
+
+// findDir finds the bookmark and dir of given keywords with matchDir, merged
+// with project bookmarks in current profile. The fallthrough profiles are
+// searched in order if nothing found, the visit is recorded in the profile
+// found. Matching and choosing run on a snapshot, so the store is not locked
+// while user is picking.
+func findDir(keywords []string, order []string, choose bookmark.Chooser, project *bookmark.Bookmarks) (*bookmark.Bookmark, string, error) {
+	var err error
+	for _, p := range lookupProfiles(profile, cfg.Fallthrough) {
+		var open func() bookmark.Store
+		switch file := profileDBFile(defaultDBFile, p); {
+		case p == profile:
+			open = openStore
+		case dbExists(file):
+			open = func() bookmark.Store { return openStoreAt(file) }
+		default:
+			continue
+		}
+
+		s := open()
+		b, loadErr := s.Load()
+		s.Close()
+		if loadErr != nil {
+			return nil, "", loadErr
+		}
+		b.SetIgnoreCase(cfg.IgnoreCase)
+		merged := b
+		if project != nil && p == profile {
+			merged = b.Merge(project)
+		}
+		var r1 *bookmark.Bookmark
+		var dir string
+		r1, dir, err = matchDir(merged, keywords, order, choose)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Visits of project bookmarks are not recorded.
+		if project != nil && p == profile {
+			if _, err := project.Get(r1.Name); err == nil {
+				return r1, dir, nil
+			}
+		}
+		err = updateStore(open(), func(b *bookmark.Bookmarks) error {
+			if err := b.Visit(r1.Name); err != nil {
+				return err
+			}
+			var err error
+			r1, err = b.Get(r1.Name)
+			return err
+		})
+		return r1, dir, err
+	}
+	return nil, "", err
+}
+
+// readProjectBookmarks reads the nearest project bookmark file from cwd, nil
+// if there is none. Problems of the file are written to warn, see
+// loadProjectBookmarks.
+func readProjectBookmarks(warn io.Writer) *bookmark.Bookmarks {
+	curr, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("pwd failed: %v\n", err)
+	}
+	file := bookmark.FindProjectFile(curr)
+	if file == "" {
+		return nil
+	}
+	return loadProjectBookmarks(file, warn)
+}
+
+// loadProjectBookmarks reads the project bookmark file, a broken file is
+// skipped and bookmarks with invalid name are dropped, with a warning to warn.
+func loadProjectBookmarks(file string, warn io.Writer) *bookmark.Bookmarks {
+	b, err := bookmark.LoadProject(file)
+	if err != nil {
+		fmt.Fprintf(warn, "Skipped project bookmarks: %v\n", err)
+		return nil
+	}
+	for _, bm := range b.ListWithFilters(nil) {
+		if err := checkBookmarkName(bm.Name); err != nil {
+			fmt.Fprintf(warn, "Skipped bookmark in project file %v: %v\n", file, err)
+			b.Delete(bm.Name)
+		}
+	}
+	return b
+}
+
+// matchOrder returns the match_order in config, --fuzzy replaces prefix with
+// fuzzy.
+func matchOrder(fuzzy bool) []string {
+	if !fuzzy || slices.Contains(cfg.MatchOrder, matchFuzzy) {
+		return cfg.MatchOrder
+	}
+	order := slices.Clone(cfg.MatchOrder)
+	if i := slices.Index(order, matchPrefix); i >= 0 {
+		order[i] = matchFuzzy
+	} else {
+		order = append([]string{matchFuzzy}, order...)
+	}
+	return order
+}
+
+// matchDir finds the bookmark and dir of given keywords.
+// A single keyword is "name" or "name/sub/dir", sub dir is resolved by
+// bookmark.ResolveSubdir. The name is matched with the strategies in order,
+// the next one is tried if nothing is found. If path is after fuzzy, a dir
+// named exactly the keyword wins over names only fuzzy matched.
+// Multiple keywords must all match, see bookmark.Query.
+// If choose is not nil, it picks one of the ambiguous matches.
+func matchDir(b *bookmark.Bookmarks, keywords []string, order []string, choose bookmark.Chooser) (*bookmark.Bookmark, string, error) {
+	if len(keywords) > 1 {
+		r1, _, err := b.Query(keywords...)
+		r1, err = chooseIfAmbiguous(r1, err, choose)
+		if err != nil {
+			return nil, "", err
+		}
+		dir, err := bookmark.ExpandPath(r1.Path)
+		return r1, dir, err
+	}
+
+	name, rel, _ := strings.Cut(keywords[0], "/")
+	exact := name
+	if b.IgnoreCase() {
+		exact = strings.ToLower(name)
+	}
+	// Only prefix matching looks up the name, so only it needs a valid name.
+	// Other strategies get the raw keyword, eg. "my-api" matches the path.
+	invalid := checkBookmarkName(exact)
+	var r1 *bookmark.Bookmark
+	err := invalid
+	for i, strategy := range order {
+		switch strategy {
+		case matchPrefix:
+			if invalid != nil {
+				continue
+			}
+			r1, _, err = b.Match(name)
+		case matchFuzzy:
+			r1, _, err = b.FuzzyMatch(name)
+			// A subsequence should not hide the dir named exactly the keyword,
+			// eg. "api" matches "alpine" but ~/src/api is wanted.
+			exactName := err == nil && r1.Name == exact
+			if !exactName && !isNotFound(err) && slices.Contains(order[i+1:], matchPath) {
+				if named := dirNamed(b, name); named != nil {
+					r1, err = named, nil
+				}
+			}
+		case matchPath:
+			r1, _, err = b.MatchPath(name)
+		}
+		if !isNotFound(err) {
+			break
+		}
+	}
+	r1, err = chooseIfAmbiguous(r1, err, choose)
+	if err != nil {
+		return nil, "", err
+	}
+	dir, err := bookmark.ExpandPath(r1.Path)
+	if err != nil || rel == "" {
+		return r1, dir, err
+	}
+	dir, err = bookmark.ResolveSubdir(dir, rel, b.IgnoreCase(), choose)
+	if err != nil {
+		return nil, "", err
+	}
+	return r1, dir, nil
+}
+
+// isNotFound returns true if err is one of the bookmark not found errors.
+func isNotFound(err error) bool {
+	for _, target := range []error{bookmark.ErrNotFound, bookmark.ErrPrefixNotFound, bookmark.ErrFuzzyNotFound,
+		bookmark.ErrPathNotFound, bookmark.ErrQueryNotFound} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// chooseIfAmbiguous lets choose pick one of the candidates if err is
+// MoreThanOneMatch and choose is not nil. The original error is kept if there
+// is no terminal to pick.
+func chooseIfAmbiguous(r1 *bookmark.Bookmark, err error, choose bookmark.Chooser) (*bookmark.Bookmark, error) {
+	var e *bookmark.Err
+	if choose == nil || !errors.Is(err, bookmark.ErrAmbiguous) || !errors.As(err, &e) {
+		return r1, err
+	}
+	picked, pickErr := choose(e.Candidates())
+	if errors.Is(pickErr, tui.ErrNoTerminal) {
+		return r1, err
+	}
+	return picked, pickErr
+}
+
+// pickCandidate lets user pick one of the candidates on terminal, with fzf if
+// it is installed, otherwise with the built-in picker. TO_PICKER=builtin
+// always uses the built-in picker.
+func pickCandidate(candidates []bookmark.Bookmark) (*bookmark.Bookmark, error) {
+	items := []string{}
+	for _, c := range candidates {
+		items = append(items, fmt.Sprintf("%v: %v", c.Name, dirShorten(c.Path, false)))
+	}
+
+	prompt := "Pick one of the matches:"
+	i, err := -1, tui.ErrNoFzf
+	if os.Getenv("TO_PICKER") != "builtin" {
+		i, err = tui.PickWithFzf(prompt, items)
+	}
+	if err == tui.ErrNoFzf {
+		i, err = tui.Pick(prompt, items)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pick failed: %w", err)
+	}
+	return &candidates[i], nil
+}
+
+// dirNamed returns the bookmark whose dir is named keyword, nil if there is
+// none or more than 1.
+func dirNamed(b *bookmark.Bookmarks, keyword string) *bookmark.Bookmark {
+	r1, _, err := b.MatchPath(keyword)
+	if err != nil {
+		return nil
+	}
+	base := filepath.Base(bookmark.TryExpandPath(r1.Path))
+	if base == keyword || b.IgnoreCase() && strings.EqualFold(base, keyword) {
+		return r1
+	}
+	return nil
+}
+
+// maxSuggestions is the max number of candidates shown in did you mean.
+const maxSuggestions = 5
+
+// didYouMean lists the names of candidates as "a, b or c".
+func didYouMean(candidates []bookmark.Bookmark) string {
+	names := []string{}
+	for i, c := range candidates {
+		if i == maxSuggestions {
+			break
+		}
+		names = append(names, c.Name)
+	}
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
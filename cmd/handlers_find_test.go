@@ -0,0 +1,251 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaopeng/to/bookmark"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDidYouMean(t *testing.T) {
+	tests := []struct {
+		n     string
+		names []string
+		want  string
+	}{
+		{n: "empty", names: nil, want: ""},
+		{n: "1", names: []string{"a"}, want: "a"},
+		{n: "2", names: []string{"a", "b"}, want: "a or b"},
+		{n: "3", names: []string{"a", "b", "c"}, want: "a, b or c"},
+		{n: "too many", names: []string{"a", "b", "c", "d", "e", "f"}, want: "a, b, c, d or e"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			candidates := []bookmark.Bookmark{}
+			for _, n := range tc.names {
+				candidates = append(candidates, bookmark.Bookmark{Name: n})
+			}
+			got := didYouMean(candidates)
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("-want, +got:\n%v", d)
+			}
+		})
+	}
+}
+
+func TestMatchDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "auth", "internal"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	b := bookmark.NewBookMarkForTesting()
+	b.Add("svcauth", filepath.Join(root, "auth"))
+	b.Tag("svcauth", []string{"prod"}, nil)
+	b.Add("svcbilling", filepath.Join(root, "billing"))
+	b.Add("web1", filepath.Join(root, "web1"))
+	b.Add("web2", filepath.Join(root, "web2"))
+	b.Add("myapi", filepath.Join(root, "my-api.v2"))
+	b.Add("alpine", filepath.Join(root, "alpine"))
+	b.Add("proj", filepath.Join(root, "src", "api"))
+
+	tests := []struct {
+		n        string
+		keywords []string
+		order    []string
+		want     string
+		err      error
+	}{
+		{n: "name", keywords: []string{"svca"}, want: "auth"},
+		{n: "sub dir", keywords: []string{"svca/in"}, want: "auth/internal"},
+		{n: "fuzzy", keywords: []string{"bil"}, order: []string{matchFuzzy}, want: "billing"},
+		{n: "path fallback", keywords: []string{"billing"}, want: "billing"},
+		{n: "prefix only", keywords: []string{"billing"}, order: []string{matchPrefix}, err: bookmark.ErrPrefixNotFound},
+		{n: "path first", keywords: []string{"auth"}, order: []string{matchPath, matchPrefix}, want: "auth"},
+		{n: "invalid name to path", keywords: []string{"my-api.v2"}, want: "my-api.v2"},
+		{n: "invalid name to fuzzy", keywords: []string{"2"}, order: []string{matchPrefix, matchFuzzy}, want: "web2"},
+		{n: "path over fuzzy", keywords: []string{"api"}, order: []string{matchPrefix, matchFuzzy, matchPath}, want: "src/api"},
+		{n: "fuzzy without path", keywords: []string{"alp"}, order: []string{matchFuzzy, matchPath}, want: "alpine"},
+		{n: "fuzzy only", keywords: []string{"apn"}, order: []string{matchFuzzy}, want: "alpine"},
+		{n: "multiple keywords", keywords: []string{"svc", "prod"}, want: "auth"},
+		{n: "ambiguous", keywords: []string{"web"}, err: bookmark.ErrAmbiguous},
+		{n: "not found", keywords: []string{"svc", "dev"}, err: bookmark.ErrQueryNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			order := tc.order
+			if order == nil {
+				order = defaultConfig().MatchOrder
+			}
+			_, got, err := matchDir(b, tc.keywords, order, nil)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("want err %v, got %v", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+			if d := cmp.Diff(filepath.Join(root, tc.want), got); d != "" {
+				t.Errorf("-want, +got:\n%v", d)
+			}
+		})
+	}
+}
+
+func TestMatchDirWithChooser(t *testing.T) {
+	b := bookmark.NewBookMarkForTesting()
+	b.Add("web1", "111")
+	b.Add("web2", "222")
+
+	choose := func(candidates []bookmark.Bookmark) (*bookmark.Bookmark, error) {
+		return &candidates[1], nil
+	}
+
+	for _, keywords := range [][]string{{"web"}, {"web", "2"}} {
+		r1, dir, err := matchDir(b, keywords, defaultConfig().MatchOrder, choose)
+		if err != nil {
+			t.Fatalf("matchDir failed: %v", err)
+		}
+		if r1.Name != "web2" || dir != "222" {
+			t.Errorf("want web2 picked, got %v %v", r1.Name, dir)
+		}
+	}
+}
+
+func TestFindDirChooserUnlocked(t *testing.T) {
+	oldCfg, oldProfile, oldDB, oldDefault, oldStore := cfg, profile, dbFile, defaultDBFile, storeFlag
+	defer func() {
+		cfg, profile, dbFile, defaultDBFile, storeFlag = oldCfg, oldProfile, oldDB, oldDefault, oldStore
+	}()
+
+	for _, store := range stores {
+		t.Run(store, func(t *testing.T) {
+			cfg, profile, storeFlag = defaultConfig(), defaultProfile, store
+			dbFile = filepath.Join(t.TempDir(), "db.json")
+			defaultDBFile = dbFile
+			root := t.TempDir()
+			err := updateBookmarks(func(b *bookmark.Bookmarks) error {
+				b.Add("web1", filepath.Join(root, "web1"))
+				return b.Add("web2", filepath.Join(root, "web2"))
+			})
+			if err != nil {
+				t.Fatalf("updateBookmarks failed: %v", err)
+			}
+
+			// The chooser writes the store like another to process would, it
+			// blocks if the store is locked while picking.
+			choose := func(candidates []bookmark.Bookmark) (*bookmark.Bookmark, error) {
+				done := make(chan error, 1)
+				go func() {
+					done <- updateBookmarks(func(b *bookmark.Bookmarks) error {
+						return b.Add("other", root)
+					})
+				}()
+				select {
+				case err := <-done:
+					if err != nil {
+						return nil, err
+					}
+				case <-time.After(5 * time.Second):
+					return nil, errors.New("store is locked while choosing")
+				}
+				return &candidates[1], nil
+			}
+
+			r1, dir, err := findDir([]string{"web"}, defaultConfig().MatchOrder, choose, nil)
+			if err != nil {
+				t.Fatalf("findDir failed: %v", err)
+			}
+			if r1.Name != "web2" || r1.Visits != 1 || dir != filepath.Join(root, "web2") {
+				t.Errorf("want web2 visited once, got %v %v %v", r1.Name, r1.Visits, dir)
+			}
+			if _, err := readBookmarks().Get("other"); err != nil {
+				t.Errorf("bookmark added while choosing is lost: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadProjectBookmarks(t *testing.T) {
+	tests := []struct {
+		n       string
+		content string
+		want    []string
+		warn    string
+	}{
+		{n: "valid", content: `{"api": "api", "web": "web"}`, want: []string{"api", "web"}},
+		{n: "invalid name", content: `{"api": "api", "my-web": "web"}`, want: []string{"api"}, warn: "my-web"},
+		{n: "malformed", content: `{"api": `, warn: "Skipped project bookmarks"},
+		{n: "no path", content: `{"api": {}}`, warn: "has no path"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), ".to.json")
+			if err := os.WriteFile(file, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			warn := &strings.Builder{}
+			b := loadProjectBookmarks(file, warn)
+			var got []string
+			if b != nil {
+				for _, bm := range b.ListWithFilters(nil) {
+					got = append(got, bm.Name)
+				}
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("-want, +got:\n%v", d)
+			}
+			if !strings.Contains(warn.String(), tc.warn) || (tc.warn == "") != (warn.Len() == 0) {
+				t.Errorf("want warning with %q, got %q", tc.warn, warn.String())
+			}
+		})
+	}
+}
+
+func TestMatchOrder(t *testing.T) {
+	tests := []struct {
+		n     string
+		order []string
+		fuzzy bool
+		want  []string
+	}{
+		{n: "not fuzzy", order: []string{matchPrefix, matchPath}, want: []string{matchPrefix, matchPath}},
+		{n: "replace prefix", order: []string{matchPath, matchPrefix}, fuzzy: true, want: []string{matchPath, matchFuzzy}},
+		{n: "has fuzzy", order: []string{matchPrefix, matchFuzzy}, fuzzy: true, want: []string{matchPrefix, matchFuzzy}},
+		{n: "no prefix", order: []string{matchPath}, fuzzy: true, want: []string{matchFuzzy, matchPath}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			old := cfg
+			defer func() { cfg = old }()
+			cfg = &config{MatchOrder: tc.order}
+
+			if d := cmp.Diff(tc.want, matchOrder(tc.fuzzy)); d != "" {
+				t.Errorf("-want, +got:\n%v", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/chaopeng/to/bookmark"
+)
+
+// defaultProfile is backed by the db file, other profiles are backed by
+// profiles/<name>.json next to it.
+const defaultProfile = "default"
+
+var profileRE = regexp.MustCompile("^[a-z][a-z0-9-]*$")
+
+func checkProfileName(name string) error {
+	if !profileRE.MatchString(name) {
+		return fmt.Errorf("given profile name %v is invalid", name)
+	}
+	return nil
+}
+
+// resolveProfile returns the --profile flag, TO_PROFILE env or the default
+// profile.
+func resolveProfile(flag string, getenv func(string) string) string {
+	if flag != "" {
+		return flag
+	}
+	if env := getenv("TO_PROFILE"); env != "" {
+		return env
+	}
+	return defaultProfile
+}
+
+// profileDBFile returns the json db file of profile.
+func profileDBFile(dbFile, profile string) string {
+	if profile == defaultProfile {
+		return dbFile
+	}
+	return filepath.Join(filepath.Dir(dbFile), "profiles", profile+".json")
+}
+
+// listProfiles lists the default profile and the profiles next to dbFile
+// sorted by name.
+func listProfiles(dbFile string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(filepath.Dir(dbFile), "profiles"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	res := []string{}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		name := strings.TrimSuffix(e.Name(), ext)
+		if (ext != ".json" && ext != ".bolt") || checkProfileName(name) != nil || slices.Contains(res, name) {
+			continue
+		}
+		res = append(res, name)
+	}
+	slices.Sort(res)
+	return append([]string{defaultProfile}, res...), nil
+}
+
+// lookupProfiles returns the profiles findMatchedDir searches in order, the
+// current one and then the fallthrough ones in config.
+func lookupProfiles(current string, fallthroughs []string) []string {
+	res := []string{current}
+	for _, p := range fallthroughs {
+		if !slices.Contains(res, p) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+func profileList() {
+	profiles, err := listProfiles(defaultDBFile)
+	if err != nil {
+		log.Fatalf("Failed to list profiles: %v\n", err)
+	}
+	sb := strings.Builder{}
+	for _, p := range profiles {
+		if p == profile {
+			sb.WriteString(blueBold.Sprintf("* %v", p))
+		} else {
+			sb.WriteString("  " + p)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Print(sb.String())
+}
+
+// validateNewProfile crashes if name is invalid or taken, returns its db file.
+func validateNewProfile(name string) string {
+	if err := checkProfileName(name); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	file := profileDBFile(defaultDBFile, name)
+	if dbExists(file) {
+		log.Fatalf("Profile %v already exists\n", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		log.Fatalf("Failed to create profile dir: %v\n", err)
+	}
+	return file
+}
+
+func profileCreate(name string) {
+	file := validateNewProfile(name)
+	if err := bookmark.New().Save(file); err != nil {
+		log.Fatalf("Create profile failed: %v\n", err)
+	}
+}
+
+func profileDelete(name string) {
+	if name == defaultProfile {
+		log.Fatalf("The %v profile can not be deleted\n", defaultProfile)
+	}
+	if err := checkProfileName(name); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	file := profileDBFile(defaultDBFile, name)
+	if !dbExists(file) {
+		log.Fatalf("Profile %v not found\n", name)
+	}
+	for _, f := range []string{file, boltFileOf(file), storeFileOf(file), file + ".lock"} {
+		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Fatalf("Delete profile failed: %v\n", err)
+		}
+	}
+}
+
+// profileCopy copies the bookmarks of from to the new profile to, in json so
+// the bolt store of to is seeded from it on first use.
+func profileCopy(from, to string) {
+	if err := checkProfileName(from); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	src := profileDBFile(defaultDBFile, from)
+	if from != defaultProfile && !dbExists(src) {
+		log.Fatalf("Profile %v not found\n", from)
+	}
+	file := validateNewProfile(to)
+	s := openStoreAt(src)
+	defer s.Close()
+	b, err := s.Load()
+	if err != nil {
+		log.Fatalf("Failed to load bookmarks: %v\n", err)
+	}
+	if err := b.Save(file); err != nil {
+		log.Fatalf("Copy profile failed: %v\n", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolveProfile(t *testing.T) {
+	tests := []struct {
+		n    string
+		flag string
+		env  string
+		want string
+	}{
+		{n: "default", want: defaultProfile},
+		{n: "env", env: "client-a", want: "client-a"},
+		{n: "flag over env", flag: "work", env: "client-a", want: "work"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			getenv := func(k string) string {
+				if k == "TO_PROFILE" {
+					return tc.env
+				}
+				return ""
+			}
+			if got := resolveProfile(tc.flag, getenv); got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	root := t.TempDir()
+	dbFile := filepath.Join(root, "db.json")
+
+	got, err := listProfiles(dbFile)
+	if err != nil {
+		t.Fatalf("listProfiles failed: %v", err)
+	}
+	if d := cmp.Diff([]string{defaultProfile}, got); d != "" {
+		t.Errorf("-want, +got:\n%v", d)
+	}
+
+	for _, f := range []string{"work.json", "work.bolt", "client-a.bolt", "notes.txt", "Bad.json"} {
+		writeFile(t, root, filepath.Join("profiles", f), "{}")
+	}
+	if want := filepath.Join(root, "profiles", "work.json"); profileDBFile(dbFile, "work") != want {
+		t.Errorf("want %v, got %v", want, profileDBFile(dbFile, "work"))
+	}
+
+	got, err = listProfiles(dbFile)
+	if err != nil {
+		t.Fatalf("listProfiles failed: %v", err)
+	}
+	if d := cmp.Diff([]string{defaultProfile, "client-a", "work"}, got); d != "" {
+		t.Errorf("-want, +got:\n%v", d)
+	}
+}
+
+func TestLookupProfiles(t *testing.T) {
+	got := lookupProfiles("work", []string{"client-a", "work", "default"})
+	if d := cmp.Diff([]string{"work", "client-a", "default"}, got); d != "" {
+		t.Errorf("-want, +got:\n%v", d)
+	}
+}
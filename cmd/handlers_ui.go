@@ -0,0 +1,101 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/chaopeng/to/bookmark"
+	"github.com/chaopeng/to/tui"
+)
+
+// browse shows the full-screen UI and prints the dir to jump to, nothing if
+// user quits.
+func browse() {
+	r1, err := tui.Browse(uiBackend{}, func(path string) string {
+		return dirShorten(path, false)
+	})
+	if err != nil {
+		log.Fatalf("UI failed: %v\n", err)
+	}
+	if r1 == nil {
+		return
+	}
+	dir, err := bookmark.ExpandPath(r1.Path)
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	err = updateBookmarks(func(b *bookmark.Bookmarks) error {
+		return b.Visit(r1.Name)
+	})
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	fmt.Println(dir)
+}
+
+// uiBackend applies the changes from UI to the store, each change is saved
+// immediately.
+type uiBackend struct{}
+
+func (uiBackend) List() ([]bookmark.Bookmark, error) {
+	s := openStore()
+	defer s.Close()
+	b, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return b.ListWithFilters(nil), nil
+}
+
+func (uiBackend) Rename(old, new string) error {
+	if err := checkBookmarkName(new); err != nil {
+		return err
+	}
+	return updateBookmarks(func(b *bookmark.Bookmarks) error {
+		return b.Rename(old, new)
+	})
+}
+
+func (uiBackend) Delete(name string) error {
+	return updateBookmarks(func(b *bookmark.Bookmarks) error {
+		return b.Delete(name)
+	})
+}
+
+func (uiBackend) Tag(name string, changes []string) error {
+	add, remove := parseTagChanges(changes)
+	if err := checkTags(append(add, remove...)); err != nil {
+		return err
+	}
+	err := updateBookmarks(func(b *bookmark.Bookmarks) error {
+		changed, err := b.Tag(name, add, remove)
+		if err == nil && !changed {
+			return errNoChange
+		}
+		return err
+	})
+	if err == errNoChange {
+		return nil
+	}
+	return err
+}
+
+func (uiBackend) SetNote(name, note string) error {
+	return updateBookmarks(func(b *bookmark.Bookmarks) error {
+		return b.SetNote(name, note)
+	})
+}
@@ -17,22 +17,55 @@ package cmd
 import (
 	"os"
 	"strings"
+
+	"github.com/chaopeng/to/bookmark"
+
+	"github.com/spf13/cobra"
 )
 
 var (
 	homeDir = os.Getenv("HOME")
 )
 
+// dirShorten shows dir under home as "~/...", unless abbreviate_home is off.
+// Portable paths "~/..." and "$VAR/..." are kept, "~" is expanded if
+// abbreviate_home is off.
 func dirShorten(dir string, color bool) string {
-	if strings.HasPrefix(dir, homeDir) {
-		sb := strings.Builder{}
-		if color {
-			sb.WriteString(cyanBold.Sprintf("~"))
-		} else {
-			sb.WriteString("~")
+	var prefix, rest string
+	switch {
+	case !cfg.AbbreviateHome && (dir == "~" || strings.HasPrefix(dir, "~/")):
+		return bookmark.TryExpandPath(dir)
+	case !cfg.AbbreviateHome:
+		return dir
+	case strings.HasPrefix(dir, "$"):
+		i := strings.Index(dir, "/")
+		if i < 0 {
+			i = len(dir)
 		}
-		sb.WriteString(strings.TrimPrefix(dir, homeDir))
-		return sb.String()
+		prefix, rest = dir[:i], dir[i:]
+	case dir == "~" || strings.HasPrefix(dir, "~/"):
+		prefix, rest = "~", dir[1:]
+	case strings.HasPrefix(dir, homeDir):
+		prefix, rest = "~", strings.TrimPrefix(dir, homeDir)
+	default:
+		return dir
+	}
+	if color {
+		prefix = cyanBold.Sprint(prefix)
+	}
+	return prefix + rest
+}
+
+// completeBookmarkNames completes the first argument with saved bookmark names.
+func completeBookmarkNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ensureConfigFileDir()
+	res := []string{}
+	b := readBookmarks()
+	for _, bm := range b.ListWithFilters([]bookmark.BookmarkFilter{bookmark.NewPrefixFilter(toComplete)}) {
+		res = append(res, bm.Name+"\t"+dirShorten(bm.Path, false))
 	}
-	return dir
+	return res, cobra.ShellCompDirectiveNoFileComp
 }
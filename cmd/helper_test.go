@@ -0,0 +1,48 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestDirShorten(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	oldHome, oldCfg := homeDir, cfg
+	defer func() { homeDir, cfg = oldHome, oldCfg }()
+	homeDir = "/home/u"
+
+	tests := []struct {
+		dir        string
+		abbreviate bool
+		want       string
+	}{
+		{dir: "/home/u/src", abbreviate: true, want: "~/src"},
+		{dir: "~/src", abbreviate: true, want: "~/src"},
+		{dir: "$WORKSPACE/api", abbreviate: true, want: "$WORKSPACE/api"},
+		{dir: "/tmp", abbreviate: true, want: "/tmp"},
+		{dir: "/home/u/src", want: "/home/u/src"},
+		{dir: "~/src", want: "/home/u/src"},
+		{dir: "$WORKSPACE/api", want: "$WORKSPACE/api"},
+	}
+
+	for _, tc := range tests {
+		cfg = defaultConfig()
+		cfg.AbbreviateHome = tc.abbreviate
+		if got := dirShorten(tc.dir, false); got != tc.want {
+			t.Errorf("dirShorten(%v) abbreviate %v: want %v, got %v", tc.dir, tc.abbreviate, tc.want, got)
+		}
+	}
+}
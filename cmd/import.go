@@ -0,0 +1,68 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/chaopeng/to/importer"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fromFlag         string
+	importFormatFlag string
+	strategyFlag     string
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: `Import dirs from other dir jumpers or exported bookmarks.`,
+	Long: fmt.Sprintf(`Import dirs from the database file of other dir jumpers with --from, one
+of %v. Names are generated from the last path component, dirs already
+bookmarked are skipped.
+
+Import bookmarks exported by "to export" with --format, one of %v. The
+file can be "-" for stdin.`, strings.Join(importer.Sources(), ", "), strings.Join(importer.DecodeFormats, ", ")),
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		if len(args) != 1 {
+			log.Fatalln("want exact 1 argument as file")
+		}
+		switch {
+		case fromFlag != "" && importFormatFlag != "":
+			log.Fatalln("want only one of --from and --format")
+		case fromFlag != "":
+			importDirs(fromFlag, args[0], dryRunFlag)
+		case importFormatFlag != "":
+			importBookmarks(importFormatFlag, importer.Strategy(strategyFlag), args[0], dryRunFlag)
+		default:
+			log.Fatalln("want --from or --format")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&fromFlag, "from", "", "the tool of the database, one of "+strings.Join(importer.Sources(), ", "))
+	importCmd.Flags().StringVar(&importFormatFlag, "format", "", "the format of exported bookmarks, one of "+strings.Join(importer.DecodeFormats, ", "))
+	importCmd.Flags().StringVar(&strategyFlag, "strategy", string(importer.StrategySkip), "what to do if the name is taken with --format: skip, overwrite or rename")
+	importCmd.Flags().BoolVarP(&dryRunFlag, "dry-run", "n", false, "only print bookmarks would be imported")
+}
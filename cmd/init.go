@@ -0,0 +1,63 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"embed"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+//go:embed shell
+var shellScripts embed.FS
+
+var supportedShells = []string{"bash", "zsh", "fish"}
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init bash|zsh|fish",
+	Short: "Print the j function and its completion for the specified shell",
+	Long: `Print the j function and its completion for the specified shell.
+
+bash: eval "$(to init bash)"
+zsh:  eval "$(to init zsh)"
+fish: to init fish | source`,
+	ValidArgs: supportedShells,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Fatalln("want exact 1 argument as shell")
+		}
+		script, err := shellInit(args[0])
+		if err != nil {
+			log.Fatalf("%v\n", err)
+		}
+		fmt.Print(script)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
+
+// shellInit returns the shell integration script for given shell.
+func shellInit(shell string) (string, error) {
+	b, err := shellScripts.ReadFile("shell/j." + shell)
+	if err != nil {
+		return "", fmt.Errorf("shell %q is not supported, want one of %v", shell, supportedShells)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShellInit(t *testing.T) {
+	for _, shell := range supportedShells {
+		t.Run(shell, func(t *testing.T) {
+			got, err := shellInit(shell)
+			if err != nil {
+				t.Fatalf("shellInit failed: %v", err)
+			}
+			if want := "to genj " + shell; !strings.Contains(got, want) {
+				t.Errorf("want script contains %q, got:\n%v", want, got)
+			}
+		})
+	}
+}
+
+func TestShellInitUnsupported(t *testing.T) {
+	if _, err := shellInit("csh"); err == nil {
+		t.Errorf("want error for unsupported shell")
+	}
+}
@@ -35,6 +35,7 @@ var listCmd = &cobra.Command{
 	Long:    `List saved bookmarks.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ensureConfigFileDir()
+		outputFlag = resolveOutput(outputFlag)
 
 		filters := []bookmark.BookmarkFilter{}
 		var dir string
@@ -52,7 +53,10 @@ var listCmd = &cobra.Command{
 			prefix = arg
 			filters = append(filters, bookmark.NewPrefixFilter(prefix))
 		}
-		listWithFilters(prefix, dir, filters)
+		if len(tagsFlag) > 0 {
+			filters = append(filters, bookmark.NewTagFilter(tagsFlag...))
+		}
+		listWithFilters(prefix, dir, tagsFlag, filters, outputFlag)
 	},
 }
 
@@ -61,4 +65,6 @@ func init() {
 
 	listCmd.Flags().BoolVarP(&currFlag, "curr", "c", false, "only list bookmarks under current dir")
 	listCmd.Flags().StringVarP(&arg, "filter", "f", "", "list bookmarks with given prefix")
+	listCmd.Flags().StringArrayVarP(&tagsFlag, "tag", "t", nil, "list bookmarks with given tag, can be repeated")
+	listCmd.Flags().StringVarP(&outputFlag, "output", "o", "", outputFlagUsage)
 }
@@ -0,0 +1,47 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// noteCmd represents the note command
+var noteCmd = &cobra.Command{
+	Use:   "note name [text]",
+	Short: `Set a one-line note of given bookmark.`,
+	Long: `Set a one-line note of given bookmark, empty text removes the note. Prints
+the note if no text given.`,
+	ValidArgsFunction: completeBookmarkNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		if len(args) < 1 {
+			log.Fatalln("want bookmark name and optional note")
+		}
+		if len(args) == 1 {
+			note(args[0], nil)
+			return
+		}
+		text := strings.Join(args[1:], " ")
+		note(args[0], &text)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chaopeng/to/bookmark"
+)
+
+// Machine readable outputs, without header and color.
+const (
+	outputJSON  = "json"
+	outputTSV   = "tsv"
+	outputPlain = "plain"
+)
+
+var outputs = []string{outputJSON, outputTSV, outputPlain}
+
+var (
+	outputFlag string
+)
+
+// outputFlagUsage is the usage of --output flag.
+var outputFlagUsage = "machine readable output, one of " + strings.Join(outputs, ", ") + ", default to output in config file"
+
+// outputBookmark is the bookmark in machine readable output, the field names
+// are stable.
+type outputBookmark struct {
+	Name string `json:"name"`
+	// Path is expanded, see bookmark.ExpandPath.
+	Path      string    `json:"path"`
+	Tags      []string  `json:"tags"`
+	Note      string    `json:"note"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	Visits    int       `json:"visits"`
+	LastVisit time.Time `json:"last_visit"`
+	// Status is "ok" or why the path can not be cd to.
+	Status string `json:"status"`
+	// Dir is the matched dir of find, it is a sub dir of Path if asked.
+	Dir string `json:"dir,omitempty"`
+}
+
+func newOutputBookmark(b *bookmark.Bookmark, dir string) outputBookmark {
+	tags := b.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return outputBookmark{
+		Name:      b.Name,
+		Path:      bookmark.TryExpandPath(b.Path),
+		Tags:      tags,
+		Note:      b.Note,
+		Created:   b.Created,
+		Updated:   b.Updated,
+		Visits:    b.Visits,
+		LastVisit: b.LastVisit,
+		Status:    bookmark.CheckStale(b.Path).String(),
+		Dir:       dir,
+	}
+}
+
+func validateOutput(output string) {
+	if output != "" && !slices.Contains(outputs, output) {
+		log.Fatalf("Unknown output %q, want one of %v\n", output, strings.Join(outputs, ", "))
+	}
+}
+
+// resolveOutput returns the validated output flag, or output in config if the
+// flag is not given.
+func resolveOutput(output string) string {
+	if output == "" {
+		return cfg.Output
+	}
+	validateOutput(output)
+	return output
+}
+
+// printOutput prints one bookmark, json is an object.
+func printOutput(output string, b outputBookmark) {
+	if output == outputJSON {
+		printJSON(b)
+		return
+	}
+	printOutputList(output, []outputBookmark{b})
+}
+
+// printOutputList prints bookmarks, json is an array, tsv and plain are one
+// line per bookmark.
+func printOutputList(output string, l []outputBookmark) {
+	if output == outputJSON {
+		printJSON(l)
+		return
+	}
+	sb := strings.Builder{}
+	for _, b := range l {
+		sb.WriteString(formatOutputLine(output, &b))
+		sb.WriteString("\n")
+	}
+	fmt.Print(sb.String())
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatalf("Failed to encode json: %v\n", err)
+	}
+}
+
+// formatOutputLine formats b as tsv: name, path, tags joined with ",", note,
+// status, and dir for find. plain is the dir of find or the path.
+func formatOutputLine(output string, b *outputBookmark) string {
+	if output == outputPlain {
+		if b.Dir != "" {
+			return b.Dir
+		}
+		return b.Path
+	}
+	fields := []string{b.Name, b.Path, strings.Join(b.Tags, ","), b.Note, b.Status}
+	if b.Dir != "" {
+		fields = append(fields, b.Dir)
+	}
+	for i, f := range fields {
+		fields[i] = tsvEscaper.Replace(f)
+	}
+	return strings.Join(fields, "\t")
+}
+
+// tsvEscaper escapes the chars can not be in a tsv field.
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
@@ -0,0 +1,76 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chaopeng/to/bookmark"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormatOutputLine(t *testing.T) {
+	dir := t.TempDir()
+	b := &bookmark.Bookmark{Name: "api", Path: dir, Tags: []string{"go", "prod"}, Note: "a\tb\\c\nd"}
+
+	tests := []struct {
+		n      string
+		output string
+		dir    string
+		want   string
+	}{
+		{n: "tsv", output: outputTSV, want: "api\t" + dir + "\tgo,prod\ta\\tb\\\\c\\nd\tok"},
+		{n: "tsv find", output: outputTSV, dir: dir + "/sub", want: "api\t" + dir + "\tgo,prod\ta\\tb\\\\c\\nd\tok\t" + dir + "/sub"},
+		{n: "plain", output: outputPlain, want: dir},
+		{n: "plain find", output: outputPlain, dir: dir + "/sub", want: dir + "/sub"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			ob := newOutputBookmark(b, tc.dir)
+			if got := formatOutputLine(tc.output, &ob); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestOutputBookmarkJSON(t *testing.T) {
+	ob := newOutputBookmark(&bookmark.Bookmark{Name: "api", Path: "/not/exist"}, "")
+	data, err := json.Marshal(ob)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	got := map[string]any{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	want := map[string]any{
+		"name":       "api",
+		"path":       "/not/exist",
+		"tags":       []any{},
+		"note":       "",
+		"created":    "0001-01-01T00:00:00Z",
+		"updated":    "0001-01-01T00:00:00Z",
+		"visits":     float64(0),
+		"last_visit": "0001-01-01T00:00:00Z",
+		"status":     "missing",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/chaopeng/to/bookmark"
+)
+
+// xdgDir returns $env or $HOME/fallback if env is not set or relative as XDG
+// base dir spec says.
+func xdgDir(getenv func(string) string, env, fallback string) string {
+	if dir := getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(getenv("HOME"), fallback)
+}
+
+// configFilePath returns the path of config file.
+func configFilePath(getenv func(string) string) string {
+	return filepath.Join(xdgDir(getenv, "XDG_CONFIG_HOME", ".config"), "to", "config.toml")
+}
+
+// expandPath expands "~" and "$VAR" like bookmark.ExpandPath and makes path
+// absolute.
+func expandPath(getenv func(string) string, path string) (string, error) {
+	expanded, err := bookmark.ExpandPathWith(path, getenv)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(expanded)
+}
+
+// resolveDBFile finds the db file, the first one set of:
+// --db flag, TO_DB env, db in config file, or the XDG data dir. The db file
+// in legacy dir $HOME/.config/to is moved to the XDG data dir once, it is
+// still used if the move fails.
+func resolveDBFile(flag string, c *config, getenv func(string) string) (string, error) {
+	if flag != "" {
+		return expandPath(getenv, flag)
+	}
+	if env := getenv("TO_DB"); env != "" {
+		return expandPath(getenv, env)
+	}
+	if c.DB != "" {
+		return expandPath(getenv, c.DB)
+	}
+
+	data := filepath.Join(xdgDir(getenv, "XDG_DATA_HOME", filepath.Join(".local", "share")), "to", "db.json")
+	legacy := filepath.Join(getenv("HOME"), ".config", "to", "db.json")
+	if dbExists(data) || !dbExists(legacy) {
+		return data, nil
+	}
+	if err := migrateDB(legacy, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to move %v to %v, keep using it: %v\n", legacy, data, err)
+		return legacy, nil
+	}
+	return data, nil
+}
+
+// migrateDB moves the db files from to, the json file last as it tells if it
+// is migrated. The moved files are moved back if any fails.
+func migrateDB(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	moved := [][2]string{}
+	for _, m := range [][2]string{
+		{boltFileOf(from), boltFileOf(to)},
+		{storeFileOf(from), storeFileOf(to)},
+		{from, to},
+	} {
+		if !exists(m[0]) {
+			continue
+		}
+		if err := moveFile(m[0], m[1]); err != nil {
+			for _, m := range moved {
+				moveFile(m[1], m[0])
+			}
+			return err
+		}
+		moved = append(moved, m)
+	}
+	return nil
+}
+
+// renameFile is os.Rename, replaced in tests.
+var renameFile = os.Rename
+
+// moveFile renames from to, or copies and removes it if they are on
+// different file systems, eg. the config dir is a link by dotfile managers.
+func moveFile(from, to string) error {
+	err := renameFile(from, to)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(to), filepath.Base(to)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), fi.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), to); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+// dbExists returns true if the json or bolt db file exists.
+func dbExists(dbFile string) bool {
+	return exists(dbFile) || exists(boltFileOf(dbFile))
+}
+
+// boltFileOf returns the bolt db file next to the json db file.
+func boltFileOf(dbFile string) string {
+	return strings.TrimSuffix(dbFile, filepath.Ext(dbFile)) + ".bolt"
+}
+
+// storeFileOf returns the file recording the active store backend of the
+// json db file.
+func storeFileOf(dbFile string) string {
+	return strings.TrimSuffix(dbFile, filepath.Ext(dbFile)) + ".store"
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
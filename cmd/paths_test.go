@@ -0,0 +1,169 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// writeFile writes content to file under dir, creates parent dirs.
+func writeFile(t *testing.T, dir, file, content string) {
+	t.Helper()
+	path := filepath.Join(dir, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+}
+
+// resolveDBFileWithConfig loads the config file and resolves the db file like
+// ensureConfigFileDir.
+func resolveDBFileWithConfig(flag string, getenv func(string) string) (string, error) {
+	c, err := loadConfig(configFilePath(getenv))
+	if err != nil {
+		return "", err
+	}
+	return resolveDBFile(flag, c, getenv)
+}
+
+func TestResolveDBFile(t *testing.T) {
+	tests := []struct {
+		n     string
+		flag  string
+		env   map[string]string
+		files map[string]string
+		want  string
+	}{
+		{
+			n:    "default xdg data",
+			want: "home/.local/share/to/db.json",
+		},
+		{
+			n:    "XDG_DATA_HOME",
+			env:  map[string]string{"XDG_DATA_HOME": "data"},
+			want: "data/to/db.json",
+		},
+		{
+			n:     "config",
+			files: map[string]string{"home/.config/to/config.toml": `db = "~/my/db.json"`},
+			want:  "home/my/db.json",
+		},
+		{
+			n:     "XDG_CONFIG_HOME",
+			env:   map[string]string{"XDG_CONFIG_HOME": "conf"},
+			files: map[string]string{"conf/to/config.toml": `db = "~/my/db.json"`},
+			want:  "home/my/db.json",
+		},
+		{
+			n:     "env var in config",
+			env:   map[string]string{"XDG_DATA_HOME": "data"},
+			files: map[string]string{"home/.config/to/config.toml": `db = "$XDG_DATA_HOME/my.json"`},
+			want:  "data/my.json",
+		},
+		{
+			n:     "env over config",
+			env:   map[string]string{"TO_DB": "env/db.json"},
+			files: map[string]string{"home/.config/to/config.toml": `db = "~/my/db.json"`},
+			want:  "env/db.json",
+		},
+		{
+			n:    "flag over env",
+			flag: "~/flag.json",
+			env:  map[string]string{"TO_DB": "env/db.json"},
+			want: "home/flag.json",
+		},
+		{
+			n: "xdg data over legacy",
+			files: map[string]string{
+				"home/.local/share/to/db.json": "{}",
+				"home/.config/to/db.json":      "{}",
+			},
+			want: "home/.local/share/to/db.json",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			root := t.TempDir()
+			for f, content := range tc.files {
+				writeFile(t, root, f, content)
+			}
+			env := map[string]string{"HOME": filepath.Join(root, "home")}
+			for k, v := range tc.env {
+				env[k] = filepath.Join(root, v)
+			}
+
+			got, err := resolveDBFileWithConfig(tc.flag, func(k string) string { return env[k] })
+			if err != nil {
+				t.Fatalf("resolveDBFile failed: %v", err)
+			}
+			if want := filepath.Join(root, tc.want); got != want {
+				t.Errorf("want %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestResolveDBFileMigrate(t *testing.T) {
+	// Rename fails across file systems, the files are copied instead.
+	crossFS := func(from, to string) error {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EXDEV}
+	}
+	defer func() { renameFile = os.Rename }()
+
+	for n, fn := range map[string]func(string, string) error{"rename": os.Rename, "cross fs": crossFS} {
+		t.Run(n, func(t *testing.T) {
+			renameFile = fn
+			root := t.TempDir()
+			writeFile(t, root, "home/.config/to/db.json", "json")
+			writeFile(t, root, "home/.config/to/db.bolt", "bolt")
+			writeFile(t, root, "home/.config/to/db.store", "bolt")
+			env := map[string]string{"HOME": filepath.Join(root, "home")}
+
+			got, err := resolveDBFileWithConfig("", func(k string) string { return env[k] })
+			if err != nil {
+				t.Fatalf("resolveDBFile failed: %v", err)
+			}
+			if want := filepath.Join(root, "home/.local/share/to/db.json"); got != want {
+				t.Errorf("want %v, got %v", want, got)
+			}
+
+			for f, want := range map[string]string{"db.json": "json", "db.bolt": "bolt", "db.store": "bolt"} {
+				content, err := os.ReadFile(filepath.Join(root, "home/.local/share/to", f))
+				if err != nil || string(content) != want {
+					t.Errorf("want %v moved, got %q %v", f, content, err)
+				}
+				if exists(filepath.Join(root, "home/.config/to", f)) {
+					t.Errorf("want legacy %v removed", f)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveDBFileBadConfig(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "home/.config/to/config.toml", "db = ")
+	env := map[string]string{"HOME": filepath.Join(root, "home")}
+
+	if _, err := resolveDBFileWithConfig("", func(k string) string { return env[k] }); err == nil {
+		t.Errorf("want error for bad config")
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: `Manage bookmark profiles.`,
+	Long: `Manage bookmark profiles. Each profile is a separated set of bookmarks,
+select one with --profile flag or TO_PROFILE env.`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   `List profiles, the current one is marked with *.`,
+	Long:    `List profiles, the current one is marked with *.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		profileList()
+	},
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create name",
+	Short: `Create an empty profile.`,
+	Long:  `Create an empty profile.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		if len(args) != 1 {
+			log.Fatalln("want exact 1 argument as profile name")
+		}
+		profileCreate(args[0])
+	},
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:               "delete name",
+	Aliases:           []string{"rm"},
+	Short:             `Delete given profile and all its bookmarks.`,
+	Long:              `Delete given profile and all its bookmarks, the default profile can not be deleted.`,
+	ValidArgsFunction: completeProfiles,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		if len(args) != 1 {
+			log.Fatalln("want exact 1 argument as profile name")
+		}
+		profileDelete(args[0])
+	},
+}
+
+var profileCopyCmd = &cobra.Command{
+	Use:               "copy from to",
+	Aliases:           []string{"cp"},
+	Short:             `Copy the bookmarks of a profile to a new profile.`,
+	Long:              `Copy the bookmarks of a profile to a new profile.`,
+	ValidArgsFunction: completeProfiles,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		if len(args) != 2 {
+			log.Fatalln("want exact 2 arguments as source and new profile name")
+		}
+		profileCopy(args[0], args[1])
+	},
+}
+
+// completeProfiles completes the first argument with profile names.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ensureConfigFileDir()
+	profiles, err := listProfiles(defaultDBFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return profiles, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd, profileCreateCmd, profileDeleteCmd, profileCopyCmd)
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// renameCmd represents the rename command
+var renameCmd = &cobra.Command{
+	Use:               "rename old new",
+	Aliases:           []string{"mv"},
+	Short:             `Rename given bookmark.`,
+	Long:              `Rename given bookmark.`,
+	ValidArgsFunction: completeBookmarkNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		if len(args) != 2 {
+			log.Fatalln("want exact 2 arguments as old and new bookmark name")
+		}
+		rename(args[0], args[1])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+}
@@ -20,16 +20,24 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 var (
-	arg string
+	arg         string
+	storeFlag   string
+	dbFlag      string
+	profileFlag string
+	tagsFlag    []string
 )
 
+// The profile and db files, resolved in ensureConfigFileDir. dbFile is the db
+// of current profile, defaultDBFile is the db of default profile.
 var (
-	dbDir  = filepath.Join(os.Getenv("HOME"), ".config", "to")
-	dbFile = filepath.Join(dbDir, "db.json")
+	profile       string
+	dbFile        string
+	defaultDBFile string
 )
 
 var rootCmd = &cobra.Command{
@@ -40,7 +48,32 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// ensureConfigFileDir loads the config file, resolves the profile and its db
+// file and creates the dir.
 func ensureConfigFileDir() {
+	var err error
+	cfg, err = loadConfig(configFilePath(os.Getenv))
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	switch cfg.Color {
+	case colorAlways:
+		color.NoColor = false
+	case colorNever:
+		color.NoColor = true
+	}
+
+	defaultDBFile, err = resolveDBFile(dbFlag, cfg, os.Getenv)
+	if err != nil {
+		log.Fatalf("Failed to find database: %v\n", err)
+	}
+	profile = resolveProfile(profileFlag, os.Getenv)
+	if err := checkProfileName(profile); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	dbFile = profileDBFile(defaultDBFile, profile)
+
+	dbDir := filepath.Dir(dbFile)
 	if _, err := os.Stat(dbDir); err != nil {
 		err := os.MkdirAll(dbDir, 0755)
 		if err != nil {
@@ -49,6 +82,12 @@ func ensureConfigFileDir() {
 	}
 }
 
+func init() {
+	rootCmd.PersistentFlags().StringVar(&dbFlag, "db", "", "path of the database file, default to TO_DB env, db in config file or $XDG_DATA_HOME/to/db.json")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "bookmark profile to use, default to TO_PROFILE env or "+defaultProfile)
+	rootCmd.PersistentFlags().StringVar(&storeFlag, "store", "", "bookmark store backend, json or bolt, default to TO_STORE env, store in config file or json")
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
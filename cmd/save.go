@@ -31,10 +31,12 @@ var saveCmd = &cobra.Command{
 		if len(args) != 1 {
 			log.Fatalln("want exact 1 argument as bookmark name")
 		}
-		save(args[0])
+		save(args[0], tagsFlag)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(saveCmd)
+
+	saveCmd.Flags().StringArrayVarP(&tagsFlag, "tag", "t", nil, "tag the bookmark, can be repeated")
 }
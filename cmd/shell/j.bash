@@ -0,0 +1,37 @@
+# Copyright 2023 chaopeng@chaopeng.me
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# to shell integration for bash, load with:
+#   eval "$(to init bash)"
+
+# j is used to actually cd to the bookmarked dir, opens the UI if no argument.
+j() {
+  local dir
+  if [ $# -eq 0 ]; then
+    dir="$(command to ui)" && [ -n "$dir" ] && cd "$dir"
+  else
+    dir="$(command to find -i -o plain "$@")" && cd "$dir"
+  fi
+}
+
+_to_j_complete() {
+  local cur="${COMP_WORDS[COMP_CWORD]}"
+  COMPREPLY=($(compgen -W "$(command to genj bash "$cur")" -- "$cur"))
+  # Keep completing sub dirs after "name/".
+  if [[ "$cur" == */* ]]; then
+    compopt -o nospace
+  fi
+}
+
+complete -F _to_j_complete j
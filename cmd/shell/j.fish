@@ -4,7 +4,7 @@
 # you may not use this file except in compliance with the License.
 # You may obtain a copy of the License at
 #
-#     http:#www.apache.org/licenses/LICENSE-2.0
+#     http://www.apache.org/licenses/LICENSE-2.0
 #
 # Unless required by applicable law or agreed to in writing, software
 # distributed under the License is distributed on an "AS IS" BASIS,
@@ -12,7 +12,23 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+# to shell integration for fish, load with:
+#   to init fish | source
+
+# j is used to actually cd to the bookmarked dir, opens the UI if no argument.
+function j
+  set -l dir
+  if test (count $argv) -eq 0
+    set dir (command to ui)
+  else
+    set dir (command to find -i -o plain $argv)
+  end
+  if test $status -eq 0 -a -n "$dir"
+    cd $dir
+  end
+end
+
 # cleanup current autocomplete
 complete -c j -e
 
-complete -f -c j -a '(to genj fish)'
+complete -f -c j -a '(to genj fish (commandline -ct))'
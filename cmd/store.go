@@ -0,0 +1,164 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/chaopeng/to/bookmark"
+)
+
+const (
+	storeJSON = "json"
+	storeBolt = "bolt"
+)
+
+var stores = []string{storeJSON, storeBolt}
+
+// openStore opens the store of current profile, crash if the profile does not
+// exist.
+func openStore() bookmark.Store {
+	if profile != defaultProfile && !dbExists(dbFile) {
+		log.Fatalf("Profile %v not found, create it with: to profile create %v\n", profile, profile)
+	}
+	return openStoreAt(dbFile)
+}
+
+// resolveStore returns the store backend, the first one set of --store flag,
+// TO_STORE env or store in config.
+func resolveStore(flag string, getenv func(string) string, c *config) string {
+	if flag != "" {
+		return flag
+	}
+	if env := getenv("TO_STORE"); env != "" {
+		return env
+	}
+	return c.Store
+}
+
+// openStoreAt opens the store of the json db file with the backend of
+// resolveStore, crash if error. The bookmarks are copied over if another
+// backend wrote them last, see syncStore.
+func openStoreAt(dbFile string) bookmark.Store {
+	kind := resolveStore(storeFlag, os.Getenv, cfg)
+	if !slices.Contains(stores, kind) {
+		log.Fatalf("Unknown store %q, want %v or %v\n", kind, storeJSON, storeBolt)
+	}
+	if err := syncStore(dbFile, kind); err != nil {
+		log.Fatalf("Failed to switch store to %v: %v\n", kind, err)
+	}
+	s, err := openBackend(dbFile, kind)
+	if err != nil {
+		log.Fatalf("Failed to open store: %v\n", err)
+	}
+	return s
+}
+
+func openBackend(dbFile, kind string) (bookmark.Store, error) {
+	if kind == storeBolt {
+		return bookmark.OpenBoltStore(boltFileOf(dbFile))
+	}
+	return bookmark.NewJSONStore(dbFile), nil
+}
+
+// activeStore returns the backend wrote the bookmarks of dbFile last. Without
+// the record, it is bolt if the bolt file exists, json otherwise.
+func activeStore(dbFile string) (string, error) {
+	content, err := os.ReadFile(storeFileOf(dbFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		if exists(boltFileOf(dbFile)) {
+			return storeBolt, nil
+		}
+		return storeJSON, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// syncStore copies the bookmarks to kind if another backend wrote them last,
+// and records kind as the active one. So switching the backend either way
+// keeps all bookmarks.
+func syncStore(dbFile, kind string) error {
+	if active, err := activeStore(dbFile); err != nil || active == kind {
+		return err
+	}
+	// Serialize with json store updates and other switches.
+	unlock, err := bookmark.Lock(dbFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	active, err := activeStore(dbFile)
+	if err != nil || active == kind {
+		return err
+	}
+
+	if err := copyStore(dbFile, active, kind); err != nil {
+		return err
+	}
+	return os.WriteFile(storeFileOf(dbFile), []byte(kind+"\n"), 0644)
+}
+
+// copyStore replaces the bookmarks in backend to with the ones in from.
+func copyStore(dbFile, from, to string) error {
+	src, err := openBackend(dbFile, from)
+	if err != nil {
+		return err
+	}
+	b, err := src.Load()
+	src.Close()
+	if err != nil {
+		return err
+	}
+	dst, err := openBackend(dbFile, to)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	return dst.Save(b)
+}
+
+// readBookmarks reads the bookmarks from store, crash if error.
+func readBookmarks() *bookmark.Bookmarks {
+	s := openStore()
+	defer s.Close()
+	b, err := s.Load()
+	if err != nil {
+		log.Fatalf("Failed to load bookmarks: %v\n", err)
+	}
+	b.SetIgnoreCase(cfg.IgnoreCase)
+	return b
+}
+
+// updateBookmarks runs fn in a store transaction of current profile.
+func updateBookmarks(fn func(b *bookmark.Bookmarks) error) error {
+	return updateStore(openStore(), fn)
+}
+
+// updateStore runs fn in a transaction of s and closes it.
+func updateStore(s bookmark.Store, fn func(b *bookmark.Bookmarks) error) error {
+	defer s.Close()
+	return s.Update(func(b *bookmark.Bookmarks) error {
+		b.SetIgnoreCase(cfg.IgnoreCase)
+		return fn(b)
+	})
+}
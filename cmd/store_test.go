@@ -0,0 +1,103 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/chaopeng/to/bookmark"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolveStore(t *testing.T) {
+	tests := []struct {
+		n      string
+		flag   string
+		env    string
+		config string
+		want   string
+	}{
+		{n: "default", want: storeJSON},
+		{n: "config", config: storeBolt, want: storeBolt},
+		{n: "env over config", env: storeJSON, config: storeBolt, want: storeJSON},
+		{n: "flag over env", flag: storeBolt, env: storeJSON, want: storeBolt},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			c := defaultConfig()
+			if tc.config != "" {
+				c.Store = tc.config
+			}
+			getenv := func(k string) string {
+				if k == "TO_STORE" {
+					return tc.env
+				}
+				return ""
+			}
+			if got := resolveStore(tc.flag, getenv, c); got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSwitchStore(t *testing.T) {
+	oldCfg, oldStore := cfg, storeFlag
+	defer func() { cfg, storeFlag = oldCfg, oldStore }()
+	cfg = defaultConfig()
+	file := filepath.Join(t.TempDir(), "db.json")
+
+	// Each step saves a bookmark with the backend, and all saved ones are
+	// expected in it.
+	steps := []struct {
+		store string
+		add   string
+		want  []string
+	}{
+		{store: storeJSON, add: "aa", want: []string{"aa"}},
+		{store: storeBolt, add: "bb", want: []string{"aa", "bb"}},
+		{store: storeJSON, add: "cc", want: []string{"aa", "bb", "cc"}},
+		{store: storeBolt, add: "dd", want: []string{"aa", "bb", "cc", "dd"}},
+		{store: storeBolt, add: "ee", want: []string{"aa", "bb", "cc", "dd", "ee"}},
+		{store: storeJSON, add: "ff", want: []string{"aa", "bb", "cc", "dd", "ee", "ff"}},
+	}
+
+	for _, step := range steps {
+		storeFlag = step.store
+		err := updateStore(openStoreAt(file), func(b *bookmark.Bookmarks) error {
+			return b.Add(step.add, "/"+step.add)
+		})
+		if err != nil {
+			t.Fatalf("add %v with %v failed: %v", step.add, step.store, err)
+		}
+
+		s := openStoreAt(file)
+		b, err := s.Load()
+		s.Close()
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		got := []string{}
+		for _, bm := range b.ListWithFilters(nil) {
+			got = append(got, bm.Name)
+		}
+		if d := cmp.Diff(step.want, got); d != "" {
+			t.Errorf("after adding %v with %v, -want, +got:\n%v", step.add, step.store, d)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// tagCmd represents the tag command
+var tagCmd = &cobra.Command{
+	Use:   "tag name [+tag|-tag]...",
+	Short: `Add or remove tags of given bookmark.`,
+	Long: `Add or remove tags of given bookmark, "+foo" or "foo" adds tag foo, "-bar"
+removes tag bar. Prints the tags if no change given.`,
+	ValidArgsFunction: completeBookmarkNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		if len(args) < 1 {
+			log.Fatalln("want bookmark name and tag changes")
+		}
+		tag(args[0], args[1:])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+
+	// Args after bookmark name like "-old" are tag changes, not flags.
+	tagCmd.Flags().SetInterspersed(false)
+}
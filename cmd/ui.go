@@ -0,0 +1,41 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// uiCmd represents the ui command
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: `Browse and manage bookmarks in terminal UI.`,
+	Long: `Browse and manage bookmarks in terminal UI. Type to filter, enter to
+print the dir to jump to, ctrl-r to rename, ctrl-x to delete, ctrl-t to tag
+and ctrl-e to edit note.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		if len(args) != 0 {
+			log.Fatalln("want no argument")
+		}
+		browse()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// updateCmd represents the update command
+var updateCmd = &cobra.Command{
+	Use:   "update name [path]",
+	Short: `Point given bookmark to path, default to current dir.`,
+	Long:  `Point given bookmark to path, default to current dir.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		}
+		return completeBookmarkNames(cmd, args, toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureConfigFileDir()
+		if len(args) != 1 && len(args) != 2 {
+			log.Fatalln("want bookmark name and optional path")
+		}
+		dir := ""
+		if len(args) == 2 {
+			dir = args[1]
+		}
+		update(args[0], dir)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+}
@@ -3,9 +3,13 @@ module github.com/chaopeng/to
 go 1.21
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/fatih/color v1.16.0
 	github.com/google/go-cmp v0.6.0
 	github.com/spf13/cobra v1.8.0
+	go.etcd.io/bbolt v1.3.8
+	golang.org/x/term v0.15.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,145 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chaopeng/to/bookmark"
+)
+
+// parseAutojump parses autojump.txt, each line is "weight\tpath".
+func parseAutojump(r io.Reader) ([]Entry, error) {
+	return parseLines(r, func(line string) (Entry, error) {
+		weight, path, ok := strings.Cut(line, "\t")
+		if !ok {
+			return Entry{}, fmt.Errorf("want weight and path separated by tab")
+		}
+		rank, err := strconv.ParseFloat(weight, 64)
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid weight: %w", err)
+		}
+		return Entry{Path: path, Rank: rank}, nil
+	})
+}
+
+// parseZ parses the data file of z and fasd, each line is "path|rank|time".
+func parseZ(r io.Reader) ([]Entry, error) {
+	return parseLines(r, func(line string) (Entry, error) {
+		// Path may contain "|", rank and time are the last 2 fields.
+		fields := strings.Split(line, "|")
+		if len(fields) < 3 {
+			return Entry{}, fmt.Errorf("want path|rank|time")
+		}
+		path := strings.Join(fields[:len(fields)-2], "|")
+		rank, err := strconv.ParseFloat(fields[len(fields)-2], 64)
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid rank: %w", err)
+		}
+		return Entry{Path: path, Rank: rank}, nil
+	})
+}
+
+var bashmarksRE = regexp.MustCompile(`^export DIR_([A-Za-z0-9_]+)=["']?(.*?)["']?$`)
+
+// parseBashmarks parses .sdirs of bashmarks, each line is
+// `export DIR_name="path"`. bashmarks writes dirs under home as "$HOME/...",
+// they are expanded by bookmark.ExpandPath.
+func parseBashmarks(r io.Reader) ([]Entry, error) {
+	return parseLines(r, func(line string) (Entry, error) {
+		m := bashmarksRE.FindStringSubmatch(line)
+		if m == nil {
+			return Entry{}, fmt.Errorf(`want export DIR_name="path"`)
+		}
+		return Entry{Name: m[1], Path: bookmark.TryExpandPath(m[2])}, nil
+	})
+}
+
+// parseLines parses non-empty lines with parse, the error tells the line
+// number.
+func parseLines(r io.Reader, parse func(line string) (Entry, error)) ([]Entry, error) {
+	res := []Entry{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		e, err := parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", n, err)
+		}
+		res = append(res, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+	return res, nil
+}
+
+// zoxideVersion is the supported version of zoxide db.zo.
+const zoxideVersion = 3
+
+// maxZoxidePath limits the path length to detect corrupted files.
+const maxZoxidePath = 1 << 16
+
+// parseZoxide parses db.zo of zoxide. It is bincode of u32 version and the
+// list of dirs, a u64 count followed by the dirs. Each dir is the path as u64
+// length and bytes, f64 rank and u64 last accessed time, all little endian.
+func parseZoxide(r io.Reader) ([]Entry, error) {
+	br := bufio.NewReader(r)
+	var version uint32
+	if err := binary.Read(br, binary.LittleEndian, &version); err != nil {
+		return nil, fmt.Errorf("failed to read version: %w", err)
+	}
+	if version != zoxideVersion {
+		return nil, fmt.Errorf("unsupported zoxide db version %v, want %v", version, zoxideVersion)
+	}
+
+	var count uint64
+	if err := binary.Read(br, binary.LittleEndian, &count); err != nil {
+		return nil, fmt.Errorf("failed to read count: %w", err)
+	}
+
+	res := []Entry{}
+	for i := uint64(0); i < count; i++ {
+		var n uint64
+		if err := binary.Read(br, binary.LittleEndian, &n); err != nil {
+			return nil, fmt.Errorf("dir %v: failed to read path length: %w", i, err)
+		}
+		if n > maxZoxidePath {
+			return nil, fmt.Errorf("dir %v: path length %v too long", i, n)
+		}
+		path := make([]byte, n)
+		if _, err := io.ReadFull(br, path); err != nil {
+			return nil, fmt.Errorf("dir %v: failed to read path: %w", i, err)
+		}
+		var fields struct {
+			Rank         float64
+			LastAccessed uint64
+		}
+		if err := binary.Read(br, binary.LittleEndian, &fields); err != nil {
+			return nil, fmt.Errorf("dir %v: failed to read rank: %w", i, err)
+		}
+		res = append(res, Entry{Path: string(path), Rank: fields.Rank})
+	}
+	return res, nil
+}
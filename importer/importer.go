@@ -0,0 +1,172 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package importer places importing dirs from other dir jumpers' databases,
+// and exporting and importing bookmarks in portable formats.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/chaopeng/to/bookmark"
+)
+
+// Entry is a dir in the database of other tool.
+type Entry struct {
+	// Name is the name given in the tool, empty if the tool does not name dirs.
+	Name string
+	Path string
+	// Rank is the score of the tool, higher ranked dirs get names first.
+	Rank float64
+}
+
+var parsers = map[string]func(r io.Reader) ([]Entry, error){
+	"autojump":  parseAutojump,
+	"bashmarks": parseBashmarks,
+	"fasd":      parseZ,
+	"z":         parseZ,
+	"zoxide":    parseZoxide,
+}
+
+// Sources lists the supported tools.
+func Sources() []string {
+	res := []string{}
+	for k := range parsers {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Parse parses the database of tool from, all paths must be absolute.
+func Parse(from string, r io.Reader) ([]Entry, error) {
+	parse, ok := parsers[from]
+	if !ok {
+		return nil, fmt.Errorf("unknown source %q, want one of %v", from, strings.Join(Sources(), ", "))
+	}
+	entries, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !filepath.IsAbs(e.Path) {
+			return nil, fmt.Errorf("path %q is not absolute", e.Path)
+		}
+	}
+	return entries, nil
+}
+
+// Renamed is the imported dir with a different name than wanted, because the
+// wanted name is taken.
+type Renamed struct {
+	Wanted, Name, Path string
+}
+
+// Skipped is the dir not imported because it is bookmarked.
+type Skipped struct {
+	Path string
+	// Existing is the name of the bookmark already has the path.
+	Existing string
+}
+
+// Report tells the result of Merge and MergeBookmarks.
+type Report struct {
+	Added       []bookmark.Bookmark
+	Overwritten []bookmark.Bookmark
+	Renamed     []Renamed
+	Skipped     []Skipped
+}
+
+// Merge adds the entries to b, higher ranked entries first. Names are given
+// by the tool or generated from the last path component, a number is
+// appended if the name is taken. Dirs already bookmarked are skipped, portable
+// paths are compared after expanded.
+func Merge(b *bookmark.Bookmarks, entries []Entry) (*Report, error) {
+	names := map[string]string{}
+	paths := map[string]string{}
+	for _, bm := range b.ListWithFilters(nil) {
+		names[bm.Name] = bm.Path
+		paths[bookmark.TryExpandPath(bm.Path)] = bm.Name
+	}
+
+	sorted := append([]Entry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Rank > sorted[j].Rank
+	})
+
+	report := &Report{}
+	for _, e := range sorted {
+		path := filepath.Clean(e.Path)
+		if existing, ok := paths[bookmark.TryExpandPath(path)]; ok {
+			report.Skipped = append(report.Skipped, Skipped{Path: path, Existing: existing})
+			continue
+		}
+
+		wanted := e.Name
+		if wanted == "" {
+			wanted = filepath.Base(path)
+		}
+		wanted = NameOf(wanted)
+		name := freeName(names, wanted)
+
+		if err := b.Add(name, path); err != nil {
+			return nil, err
+		}
+		names[name] = path
+		paths[bookmark.TryExpandPath(path)] = name
+		bm, err := b.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		report.Added = append(report.Added, *bm)
+		if name != wanted {
+			report.Renamed = append(report.Renamed, Renamed{Wanted: wanted, Name: name, Path: path})
+		}
+	}
+	return report, nil
+}
+
+// freeName appends a number to wanted if it is taken in names.
+func freeName(names map[string]string, wanted string) string {
+	name := wanted
+	for i := 2; names[name] != ""; i++ {
+		name = wanted + strconv.Itoa(i)
+	}
+	return name
+}
+
+// NameOf converts s to a valid bookmark name: lower case letters and digits,
+// starts with a letter.
+func NameOf(s string) string {
+	sb := strings.Builder{}
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLower(r) || unicode.IsDigit(r)) {
+			sb.WriteRune(r)
+		}
+	}
+	name := sb.String()
+	if name == "" {
+		return "dir"
+	}
+	if name[0] < 'a' || name[0] > 'z' {
+		return "d" + name
+	}
+	return name
+}
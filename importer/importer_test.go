@@ -0,0 +1,203 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/chaopeng/to/bookmark"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParse(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	tests := []struct {
+		from string
+		file string
+		want []Entry
+	}{
+		{
+			from: "autojump",
+			file: "autojump.txt",
+			want: []Entry{
+				{Path: "/home/u/src/to", Rank: 22.4},
+				{Path: "/home/u/My Docs", Rank: 10},
+				{Path: "/home/u/src/web-app", Rank: 3.5},
+			},
+		},
+		{
+			from: "z",
+			file: "z",
+			want: []Entry{
+				{Path: "/home/u/src/to", Rank: 12.5},
+				{Path: "/home/u/a|b", Rank: 1},
+				{Path: "/home/u/2023", Rank: 3},
+			},
+		},
+		{
+			from: "fasd",
+			file: "fasd",
+			want: []Entry{
+				{Path: "/home/u/src/to", Rank: 30},
+				{Path: "/home/u/notes.txt", Rank: 2.5},
+			},
+		},
+		{
+			from: "bashmarks",
+			file: "sdirs",
+			want: []Entry{
+				{Name: "work", Path: "/home/u/work"},
+				{Name: "My_Proj", Path: "/home/u/proj"},
+				{Name: "tmp", Path: "/tmp"},
+			},
+		},
+		{
+			from: "zoxide",
+			file: "db.zo",
+			want: []Entry{
+				{Path: "/home/u/src/to", Rank: 20.5},
+				{Path: "/home/u/.config", Rank: 3},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.from, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tc.file))
+			if err != nil {
+				t.Fatalf("open fixture failed: %v", err)
+			}
+			defer f.Close()
+			got, err := Parse(tc.from, f)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+}
+
+func TestParseErr(t *testing.T) {
+	tests := []struct {
+		n     string
+		from  string
+		input string
+	}{
+		{n: "unknown source", from: "cd", input: ""},
+		{n: "autojump no tab", from: "autojump", input: "10 /a\n"},
+		{n: "autojump bad weight", from: "autojump", input: "x\t/a\n"},
+		{n: "z missing fields", from: "z", input: "/a|1\n"},
+		{n: "z relative", from: "z", input: "a|1|1\n"},
+		{n: "bashmarks", from: "bashmarks", input: "DIR_a=/a\n"},
+		{n: "zoxide version", from: "zoxide", input: "\x02\x00\x00\x00"},
+		{n: "zoxide truncated", from: "zoxide", input: "\x03\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x05"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			if _, err := Parse(tc.from, strings.NewReader(tc.input)); err == nil {
+				t.Errorf("want error")
+			}
+		})
+	}
+}
+
+var bookmarkRE = regexp.MustCompile("^[a-z][a-z0-9]*$")
+
+func TestNameOf(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "to", want: "to"},
+		{input: "web-app", want: "webapp"},
+		{input: "My_Proj", want: "myproj"},
+		{input: "2023", want: "d2023"},
+		{input: ".config", want: "config"},
+		{input: "文档", want: "dir"},
+		{input: "/", want: "dir"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			got := NameOf(tc.input)
+			if got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+			if !bookmarkRE.MatchString(got) {
+				t.Errorf("%v is not a valid bookmark name", got)
+			}
+		})
+	}
+}
+
+var ignoreTimes = cmpopts.IgnoreFields(bookmark.Bookmark{}, "Created", "Updated")
+
+func TestMerge(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	b := bookmark.NewBookMarkForTesting()
+	b.Add("to", "/home/u/to")
+	// Portable paths are compared after expanded.
+	b.Add("web", "~/web")
+
+	entries := []Entry{
+		{Path: "/home/u/other/to", Rank: 1},
+		{Path: "/home/u/src/to/", Rank: 5},
+		{Path: "/home/u/web", Rank: 3},
+		{Name: "Work", Path: "/home/u/work"},
+		{Path: "/home/u/work", Rank: 2},
+	}
+	got, err := Merge(b, entries)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	want := &Report{
+		Added: []bookmark.Bookmark{
+			{Name: "to2", Path: "/home/u/src/to"},
+			{Name: "work", Path: "/home/u/work"},
+			{Name: "to3", Path: "/home/u/other/to"},
+		},
+		Renamed: []Renamed{
+			{Wanted: "to", Name: "to2", Path: "/home/u/src/to"},
+			{Wanted: "to", Name: "to3", Path: "/home/u/other/to"},
+		},
+		Skipped: []Skipped{
+			{Path: "/home/u/web", Existing: "web"},
+			{Path: "/home/u/work", Existing: "work"},
+		},
+	}
+	if diff := cmp.Diff(want, got, ignoreTimes); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	wantAll := []bookmark.Bookmark{
+		{Name: "to", Path: "/home/u/to"},
+		{Name: "to2", Path: "/home/u/src/to"},
+		{Name: "to3", Path: "/home/u/other/to"},
+		{Name: "web", Path: "~/web"},
+		{Name: "work", Path: "/home/u/work"},
+	}
+	if diff := cmp.Diff(wantAll, b.ListWithFilters(nil), ignoreTimes); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/chaopeng/to/bookmark"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Portable formats to export and import bookmarks, sh is export only.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatYAML = "yaml"
+	FormatSh   = "sh"
+)
+
+// ExportFormats lists the formats Export supports.
+var ExportFormats = []string{FormatJSON, FormatCSV, FormatYAML, FormatSh}
+
+// DecodeFormats lists the formats Decode supports.
+var DecodeFormats = []string{FormatJSON, FormatCSV, FormatYAML}
+
+// portable is the bookmark in exported files, only the fields worth sharing.
+type portable struct {
+	Name string   `json:"name" yaml:"name"`
+	Path string   `json:"path" yaml:"path"`
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Note string   `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+// csvHeader is the first row of csv, tags are joined with tagSep.
+var csvHeader = []string{"name", "path", "tags", "note"}
+
+const tagSep = ";"
+
+// Export writes bookmarks to w in format, portable paths are kept except in sh.
+func Export(w io.Writer, format string, bookmarks []bookmark.Bookmark) error {
+	l := []portable{}
+	for _, bm := range bookmarks {
+		l = append(l, portable{Name: bm.Name, Path: bm.Path, Tags: bm.Tags, Note: bm.Note})
+	}
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(l)
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(csvHeader)
+		for _, p := range l {
+			cw.Write([]string{p.Name, p.Path, strings.Join(p.Tags, tagSep), p.Note})
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(l); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatSh:
+		sb := strings.Builder{}
+		sb.WriteString("# Bookmarks exported from to, source this file to add j_<name> aliases.\n")
+		for _, p := range l {
+			fmt.Fprintf(&sb, "alias j_%v=%v\n", p.Name, shQuote("cd -- "+shQuote(bookmark.TryExpandPath(p.Path))))
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+	return fmt.Errorf("unknown format %q, want one of %v", format, strings.Join(ExportFormats, ", "))
+}
+
+// shQuote quotes s in single quotes for shell.
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Decode reads bookmarks exported in format, all paths must be absolute or
+// portable, see bookmark.IsPortable.
+func Decode(r io.Reader, format string) ([]bookmark.Bookmark, error) {
+	l := []portable{}
+	switch format {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&l); err != nil {
+			return nil, fmt.Errorf("failed to decode json: %w", err)
+		}
+	case FormatCSV:
+		rows, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode csv: %w", err)
+		}
+		if len(rows) == 0 || !slices.Equal(rows[0], csvHeader) {
+			return nil, fmt.Errorf("want csv header %v", strings.Join(csvHeader, ","))
+		}
+		for _, row := range rows[1:] {
+			p := portable{Name: row[0], Path: row[1], Note: row[3]}
+			if row[2] != "" {
+				p.Tags = strings.Split(row[2], tagSep)
+			}
+			l = append(l, p)
+		}
+	case FormatYAML:
+		if err := yaml.NewDecoder(r).Decode(&l); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to decode yaml: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q, want one of %v", format, strings.Join(DecodeFormats, ", "))
+	}
+
+	res := []bookmark.Bookmark{}
+	for _, p := range l {
+		if !filepath.IsAbs(p.Path) && !bookmark.IsPortable(p.Path) {
+			return nil, fmt.Errorf("path %q of %v is not absolute", p.Path, p.Name)
+		}
+		res = append(res, bookmark.Bookmark{Name: p.Name, Path: p.Path, Tags: p.Tags, Note: p.Note})
+	}
+	return res, nil
+}
+
+// Strategy tells MergeBookmarks what to do if the name is taken.
+type Strategy string
+
+const (
+	// StrategySkip keeps the existing bookmark.
+	StrategySkip Strategy = "skip"
+	// StrategyOverwrite replaces the path, tags and note of existing bookmark.
+	StrategyOverwrite Strategy = "overwrite"
+	// StrategyRename appends a number to the name of imported bookmark.
+	StrategyRename Strategy = "rename"
+)
+
+// Strategies lists the supported strategies.
+var Strategies = []Strategy{StrategySkip, StrategyOverwrite, StrategyRename}
+
+// MergeBookmarks adds the decoded bookmarks to b, conflicts are resolved with
+// strategy. Bookmarks same as existing are skipped unless overwrite.
+func MergeBookmarks(b *bookmark.Bookmarks, bookmarks []bookmark.Bookmark, strategy Strategy) (*Report, error) {
+	if !slices.Contains(Strategies, strategy) {
+		return nil, fmt.Errorf("unknown strategy %q", strategy)
+	}
+	names := map[string]string{}
+	for _, bm := range b.ListWithFilters(nil) {
+		names[bm.Name] = bm.Path
+	}
+
+	report := &Report{}
+	for _, bm := range bookmarks {
+		path := filepath.Clean(bm.Path)
+		name := bm.Name
+		existing, taken := names[name]
+		switch {
+		case taken && strategy == StrategyOverwrite:
+			if err := overwrite(b, name, path, bm.Tags, bm.Note); err != nil {
+				return nil, err
+			}
+			got, err := b.Get(name)
+			if err != nil {
+				return nil, err
+			}
+			report.Overwritten = append(report.Overwritten, *got)
+			names[name] = path
+			continue
+		case taken && (strategy == StrategySkip || bookmark.TryExpandPath(existing) == bookmark.TryExpandPath(path)):
+			report.Skipped = append(report.Skipped, Skipped{Path: path, Existing: name})
+			continue
+		case taken:
+			name = freeName(names, name)
+		}
+
+		if err := b.Add(name, path); err != nil {
+			return nil, err
+		}
+		if err := overwrite(b, name, path, bm.Tags, bm.Note); err != nil {
+			return nil, err
+		}
+		names[name] = path
+		got, err := b.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		report.Added = append(report.Added, *got)
+		if name != bm.Name {
+			report.Renamed = append(report.Renamed, Renamed{Wanted: bm.Name, Name: name, Path: path})
+		}
+	}
+	return report, nil
+}
+
+// overwrite sets the path, tags and note of bookmark name.
+func overwrite(b *bookmark.Bookmarks, name, path string, tags []string, note string) error {
+	old, err := b.Get(name)
+	if err != nil {
+		return err
+	}
+	if err := b.Update(name, path); err != nil {
+		return err
+	}
+	remove := []string{}
+	for _, t := range old.Tags {
+		if !slices.Contains(tags, t) {
+			remove = append(remove, t)
+		}
+	}
+	if _, err := b.Tag(name, tags, remove); err != nil {
+		return err
+	}
+	return b.SetNote(name, note)
+}
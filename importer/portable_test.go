@@ -0,0 +1,195 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/chaopeng/to/bookmark"
+	"github.com/google/go-cmp/cmp"
+)
+
+var exported = []bookmark.Bookmark{
+	{Name: "api", Path: "/src/api", Tags: []string{"go", "prod"}, Note: "the api, \"v2\""},
+	{Name: "docs", Path: "/home/u/it's docs"},
+}
+
+func TestExportDecode(t *testing.T) {
+	for _, format := range DecodeFormats {
+		t.Run(format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := Export(buf, format, exported); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			got, err := Decode(buf, format)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if diff := cmp.Diff(exported, got); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: FormatCSV,
+			want: `name,path,tags,note
+api,/src/api,go;prod,"the api, ""v2"""
+docs,/home/u/it's docs,,
+`,
+		},
+		{
+			format: FormatSh,
+			want: `# Bookmarks exported from to, source this file to add j_<name> aliases.
+alias j_api='cd -- '\''/src/api'\'''
+alias j_docs='cd -- '\''/home/u/it'\''\'\'''\''s docs'\'''
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := Export(buf, tc.format, exported); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, buf.String()); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+
+	if err := Export(&bytes.Buffer{}, "xml", exported); err == nil {
+		t.Errorf("want error for unknown format")
+	}
+}
+
+func TestDecodeErr(t *testing.T) {
+	tests := []struct {
+		n      string
+		format string
+		input  string
+	}{
+		{n: "unknown format", format: FormatSh, input: ""},
+		{n: "json", format: FormatJSON, input: `{"name":"a"}`},
+		{n: "json relative", format: FormatJSON, input: `[{"name":"a","path":"a"}]`},
+		{n: "csv header", format: FormatCSV, input: "a,/a,,\n"},
+		{n: "csv fields", format: FormatCSV, input: "name,path,tags,note\na,/a\n"},
+		{n: "yaml", format: FormatYAML, input: "name: a\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			if _, err := Decode(strings.NewReader(tc.input), tc.format); err == nil {
+				t.Errorf("want error")
+			}
+		})
+	}
+}
+
+func TestMergeBookmarks(t *testing.T) {
+	imported := []bookmark.Bookmark{
+		{Name: "api", Path: "/new/api", Tags: []string{"new"}, Note: "new api"},
+		{Name: "docs", Path: "/src/docs"},
+		{Name: "web", Path: "/src/web", Tags: []string{"fe"}},
+	}
+
+	tests := []struct {
+		strategy Strategy
+		want     *Report
+		wantAll  []bookmark.Bookmark
+	}{
+		{
+			strategy: StrategySkip,
+			want: &Report{
+				Added: []bookmark.Bookmark{{Name: "web", Path: "/src/web", Tags: []string{"fe"}}},
+				Skipped: []Skipped{
+					{Path: "/new/api", Existing: "api"},
+					{Path: "/src/docs", Existing: "docs"},
+				},
+			},
+			wantAll: []bookmark.Bookmark{
+				{Name: "api", Path: "/src/api", Tags: []string{"prod"}, Note: "api"},
+				{Name: "docs", Path: "/src/docs"},
+				{Name: "web", Path: "/src/web", Tags: []string{"fe"}},
+			},
+		},
+		{
+			strategy: StrategyOverwrite,
+			want: &Report{
+				Added: []bookmark.Bookmark{{Name: "web", Path: "/src/web", Tags: []string{"fe"}}},
+				Overwritten: []bookmark.Bookmark{
+					{Name: "api", Path: "/new/api", Tags: []string{"new"}, Note: "new api"},
+					{Name: "docs", Path: "/src/docs"},
+				},
+			},
+			wantAll: []bookmark.Bookmark{
+				{Name: "api", Path: "/new/api", Tags: []string{"new"}, Note: "new api"},
+				{Name: "docs", Path: "/src/docs"},
+				{Name: "web", Path: "/src/web", Tags: []string{"fe"}},
+			},
+		},
+		{
+			strategy: StrategyRename,
+			want: &Report{
+				Added: []bookmark.Bookmark{
+					{Name: "api2", Path: "/new/api", Tags: []string{"new"}, Note: "new api"},
+					{Name: "web", Path: "/src/web", Tags: []string{"fe"}},
+				},
+				Renamed: []Renamed{{Wanted: "api", Name: "api2", Path: "/new/api"}},
+				Skipped: []Skipped{{Path: "/src/docs", Existing: "docs"}},
+			},
+			wantAll: []bookmark.Bookmark{
+				{Name: "api", Path: "/src/api", Tags: []string{"prod"}, Note: "api"},
+				{Name: "api2", Path: "/new/api", Tags: []string{"new"}, Note: "new api"},
+				{Name: "docs", Path: "/src/docs"},
+				{Name: "web", Path: "/src/web", Tags: []string{"fe"}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			b := bookmark.NewBookMarkForTesting()
+			b.Add("api", "/src/api")
+			b.Tag("api", []string{"prod"}, nil)
+			b.SetNote("api", "api")
+			b.Add("docs", "/src/docs")
+
+			got, err := MergeBookmarks(b, imported, tc.strategy)
+			if err != nil {
+				t.Fatalf("MergeBookmarks failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, ignoreTimes); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+			if diff := cmp.Diff(tc.wantAll, b.ListWithFilters(nil), ignoreTimes); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+
+	if _, err := MergeBookmarks(bookmark.NewBookMarkForTesting(), imported, "merge"); err == nil {
+		t.Errorf("want error for unknown strategy")
+	}
+}
@@ -16,5 +16,4 @@
 
 go install
 
-cp scripts/fish/conf.d/j.fish         ~/.config/fish/conf.d/j.fish
-cp scripts/fish/completions/j.fish    ~/.config/fish/completions/j.fish
+to init fish > ~/.config/fish/conf.d/j.fish
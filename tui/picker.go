@@ -0,0 +1,211 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ANSI escape sequences.
+const (
+	reverse   = "\x1b[7m"
+	bold      = "\x1b[1m"
+	reset     = "\x1b[0m"
+	clearDown = "\x1b[J"
+)
+
+// maxPickerHeight is the max number of items shown at once.
+const maxPickerHeight = 10
+
+// picker is the state of the inline picker.
+type picker struct {
+	prompt string
+	items  []string
+	cursor int
+	offset int
+	height int
+	width  int
+}
+
+func newPicker(prompt string, items []string, width, height int) *picker {
+	h := min(len(items), maxPickerHeight, height-1)
+	return &picker{
+		prompt: prompt,
+		items:  items,
+		height: max(h, 1),
+		width:  width,
+	}
+}
+
+// handle updates the state with key, returns true if picked or canceled.
+func (p *picker) handle(k Key) (done bool, err error) {
+	switch k.Code {
+	case KeyUp, KeyCtrlP:
+		p.move(-1)
+	case KeyDown, KeyCtrlN, KeyTab:
+		p.move(1)
+	case KeyPageUp:
+		p.move(-p.height)
+	case KeyPageDown:
+		p.move(p.height)
+	case KeyEnter:
+		return true, nil
+	case KeyEsc, KeyCtrlC, KeyCtrlD:
+		return true, ErrCanceled
+	case KeyRune:
+		switch {
+		case k.Rune == 'k':
+			p.move(-1)
+		case k.Rune == 'j':
+			p.move(1)
+		case k.Rune == 'q':
+			return true, ErrCanceled
+		case k.Rune >= '1' && k.Rune <= '9':
+			// Digits pick the visible items directly.
+			i := p.offset + int(k.Rune-'1')
+			if i < len(p.items) && i < p.offset+p.height {
+				p.cursor = i
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// move moves the cursor by delta and scrolls to keep it visible.
+func (p *picker) move(delta int) {
+	p.cursor = max(0, min(len(p.items)-1, p.cursor+delta))
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+p.height {
+		p.offset = p.cursor - p.height + 1
+	}
+}
+
+// render returns the lines to draw, the prompt and the visible items.
+func (p *picker) render() []string {
+	lines := []string{bold + truncate(p.prompt, p.width) + reset}
+	for i := p.offset; i < len(p.items) && i < p.offset+p.height; i++ {
+		line := truncate(fmt.Sprintf("%v %v", i-p.offset+1, p.items[i]), p.width)
+		if i == p.cursor {
+			line = reverse + line + reset
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncate cuts s to fit in width columns, it assumes 1 column per rune.
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if width <= 1 || len(r) < width {
+		return s
+	}
+	return string(r[:width-2]) + "…"
+}
+
+// Pick shows an inline picker on the terminal, returns the index of picked
+// item or ErrCanceled.
+func Pick(prompt string, items []string) (int, error) {
+	t, err := OpenTerminal()
+	if err != nil {
+		return 0, err
+	}
+	defer t.Close()
+
+	w, h := t.Size()
+	p := newPicker(prompt, items, w, h)
+	drawn := 0
+	for {
+		lines := p.render()
+		redraw(t, drawn, lines)
+		drawn = len(lines)
+
+		k, err := t.ReadKey()
+		if err != nil {
+			return 0, err
+		}
+		done, err := p.handle(k)
+		if done {
+			redraw(t, drawn, nil)
+			return p.cursor, err
+		}
+	}
+}
+
+// redraw replaces the drawn lines with given, the cursor is kept at the end
+// of last line.
+func redraw(t *Terminal, drawn int, lines []string) {
+	var sb strings.Builder
+	if drawn > 1 {
+		fmt.Fprintf(&sb, "\x1b[%vA", drawn-1)
+	}
+	sb.WriteString("\r")
+	sb.WriteString(clearDown)
+	sb.WriteString(strings.Join(lines, "\r\n"))
+	t.Write(sb.String())
+}
+
+// ErrNoFzf returned if fzf is not in PATH.
+var ErrNoFzf = errors.New("fzf not found")
+
+// PickWithFzf lets fzf pick one of the items, returns the index of picked
+// item, ErrCanceled, ErrNoFzf or ErrNoTerminal.
+func PickWithFzf(prompt string, items []string) (int, error) {
+	path, err := exec.LookPath("fzf")
+	if err != nil {
+		return 0, ErrNoFzf
+	}
+	// fzf draws on /dev/tty, it fails with a bare exit status without it.
+	tty, err := os.OpenFile(ttyFile, os.O_RDWR, 0)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrNoTerminal, err)
+	}
+	tty.Close()
+
+	// Prefix items with the index to find the picked one, fzf hides it.
+	var in strings.Builder
+	for i, item := range items {
+		fmt.Fprintf(&in, "%v\t%v\n", i, item)
+	}
+
+	cmd := exec.Command(path, "--height=40%", "--reverse",
+		"--delimiter=\t", "--with-nth=2..", "--header="+prompt)
+	cmd.Stdin = strings.NewReader(in.String())
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	// fzf exits with 130 if canceled, 1 if no match.
+	if errors.As(err, &exitErr) && (exitErr.ExitCode() == 130 || exitErr.ExitCode() == 1) {
+		return 0, ErrCanceled
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	idx, _, _ := bytes.Cut(out, []byte("\t"))
+	i, err := strconv.Atoi(string(idx))
+	if err != nil || i < 0 || i >= len(items) {
+		return 0, fmt.Errorf("unexpected fzf output %q", out)
+	}
+	return i, nil
+}
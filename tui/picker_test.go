@@ -0,0 +1,107 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPickerHandle(t *testing.T) {
+	items := []string{}
+	for i := 0; i < 15; i++ {
+		items = append(items, fmt.Sprintf("item%v", i))
+	}
+
+	tests := []struct {
+		n          string
+		keys       []Key
+		wantCursor int
+		wantOffset int
+		wantDone   bool
+		wantErr    error
+	}{
+		{n: "enter", keys: []Key{{Code: KeyEnter}}, wantCursor: 0, wantDone: true},
+		{n: "down", keys: []Key{{Code: KeyDown}, {Code: KeyRune, Rune: 'j'}}, wantCursor: 2},
+		{n: "up at top", keys: []Key{{Code: KeyUp}}, wantCursor: 0},
+		{n: "scroll", keys: []Key{{Code: KeyPageDown}, {Code: KeyPageDown}}, wantCursor: 14, wantOffset: 5},
+		{n: "scroll back", keys: []Key{{Code: KeyPageDown}, {Code: KeyPageDown}, {Code: KeyPageUp}},
+			wantCursor: 4, wantOffset: 4},
+		{n: "digit", keys: []Key{{Code: KeyRune, Rune: '3'}}, wantCursor: 2, wantDone: true},
+		{n: "cancel", keys: []Key{{Code: KeyEsc}}, wantDone: true, wantErr: ErrCanceled},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			p := newPicker("pick", items, 80, 24)
+			var done bool
+			var err error
+			for _, k := range tc.keys {
+				done, err = p.handle(k)
+			}
+			if done != tc.wantDone || err != tc.wantErr {
+				t.Errorf("want done %v err %v, got done %v err %v", tc.wantDone, tc.wantErr, done, err)
+			}
+			if p.cursor != tc.wantCursor || p.offset != tc.wantOffset {
+				t.Errorf("want cursor %v offset %v, got cursor %v offset %v",
+					tc.wantCursor, tc.wantOffset, p.cursor, p.offset)
+			}
+		})
+	}
+}
+
+func TestPickerRender(t *testing.T) {
+	p := newPicker("pick one", []string{"aab1", "aab2"}, 80, 24)
+	p.handle(Key{Code: KeyDown})
+
+	want := []string{
+		bold + "pick one" + reset,
+		"1 aab1",
+		reverse + "2 aab2" + reset,
+	}
+	if diff := cmp.Diff(want, p.render()); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("abcdef", 10); got != "abcdef" {
+		t.Errorf("want not truncated, got %v", got)
+	}
+	if got := truncate("abcdef", 5); got != "abc…" {
+		t.Errorf("want truncated, got %v", got)
+	}
+}
+
+func TestPickWithFzfNoTerminal(t *testing.T) {
+	// The fake fzf fails like fzf does without a terminal.
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fzf"), []byte("#!/bin/sh\nexit 2\n"), 0755); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	t.Setenv("PATH", dir)
+	old := ttyFile
+	defer func() { ttyFile = old }()
+	ttyFile = filepath.Join(dir, "no-tty")
+
+	if _, err := PickWithFzf("pick one", []string{"a", "b"}); !errors.Is(err, ErrNoTerminal) {
+		t.Errorf("want ErrNoTerminal, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tui places terminal UI, eg. picker for ambiguous matches.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ErrCanceled returned if user cancels the UI.
+var ErrCanceled = errors.New("canceled")
+
+// ErrNoTerminal returned if the terminal can not be opened, eg. not running
+// in a terminal.
+var ErrNoTerminal = errors.New("no terminal")
+
+// KeyCode is the kind of key pressed.
+type KeyCode int
+
+const (
+	KeyUnknown KeyCode = iota
+	KeyRune
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPageUp
+	KeyPageDown
+	KeyEnter
+	KeyEsc
+	KeyTab
+	KeyBackspace
+	KeyCtrlC
+	KeyCtrlD
+	KeyCtrlE
+	KeyCtrlN
+	KeyCtrlP
+	KeyCtrlR
+	KeyCtrlT
+	KeyCtrlU
+	KeyCtrlX
+)
+
+// Key is a key press, Rune is set for KeyRune.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// escapeKeys maps escape sequences to keys.
+var escapeKeys = map[string]KeyCode{
+	"\x1b":    KeyEsc,
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1b[C":  KeyRight,
+	"\x1b[D":  KeyLeft,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1bOC":  KeyRight,
+	"\x1bOD":  KeyLeft,
+	"\x1b[5~": KeyPageUp,
+	"\x1b[6~": KeyPageDown,
+}
+
+// controlKeys maps control chars to keys.
+var controlKeys = map[byte]KeyCode{
+	'\r': KeyEnter,
+	'\n': KeyEnter,
+	'\t': KeyTab,
+	0x7f: KeyBackspace,
+	'\b': KeyBackspace,
+	0x03: KeyCtrlC,
+	0x04: KeyCtrlD,
+	0x05: KeyCtrlE,
+	0x0e: KeyCtrlN,
+	0x10: KeyCtrlP,
+	0x12: KeyCtrlR,
+	0x14: KeyCtrlT,
+	0x15: KeyCtrlU,
+	0x18: KeyCtrlX,
+}
+
+// parseKey parses the bytes of one read from terminal. Escape sequences are
+// expected to arrive in one read.
+func parseKey(b []byte) Key {
+	if len(b) == 0 {
+		return Key{Code: KeyUnknown}
+	}
+	if b[0] == 0x1b {
+		if code, ok := escapeKeys[string(b)]; ok {
+			return Key{Code: code}
+		}
+		return Key{Code: KeyUnknown}
+	}
+	if code, ok := controlKeys[b[0]]; ok {
+		return Key{Code: code}
+	}
+	r, _ := utf8.DecodeRune(b)
+	if r == utf8.RuneError || r < 0x20 {
+		return Key{Code: KeyUnknown}
+	}
+	return Key{Code: KeyRune, Rune: r}
+}
+
+// parseKeys parses the bytes of one read to keys, a read may contain more
+// than one key if text is pasted.
+func parseKeys(b []byte) []Key {
+	if len(b) > 0 && b[0] == 0x1b {
+		return []Key{parseKey(b)}
+	}
+	res := []Key{}
+	for len(b) > 0 {
+		n := 1
+		if _, ok := controlKeys[b[0]]; !ok {
+			_, n = utf8.DecodeRune(b)
+		}
+		res = append(res, parseKey(b[:n]))
+		b = b[n:]
+	}
+	return res
+}
+
+// Terminal is the controlling terminal in raw mode. UI is drawn on it so
+// stdout is kept for the result.
+type Terminal struct {
+	f       *os.File
+	state   *term.State
+	pending []Key
+}
+
+// ttyFile is the controlling terminal, replaced in tests.
+var ttyFile = "/dev/tty"
+
+// OpenTerminal opens /dev/tty in raw mode, fails if there is no terminal.
+func OpenTerminal() (*Terminal, error) {
+	f, err := os.OpenFile(ttyFile, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoTerminal, err)
+	}
+	state, err := term.MakeRaw(int(f.Fd()))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%w: %v", ErrNoTerminal, err)
+	}
+	return &Terminal{f: f, state: state}, nil
+}
+
+// Close restores the terminal mode.
+func (t *Terminal) Close() error {
+	err := term.Restore(int(t.f.Fd()), t.state)
+	t.f.Close()
+	return err
+}
+
+// ReadKey blocks until a key is pressed.
+func (t *Terminal) ReadKey() (Key, error) {
+	for len(t.pending) == 0 {
+		buf := make([]byte, 256)
+		n, err := t.f.Read(buf)
+		if err != nil {
+			return Key{}, err
+		}
+		t.pending = parseKeys(buf[:n])
+	}
+	k := t.pending[0]
+	t.pending = t.pending[1:]
+	return k, nil
+}
+
+// Write writes s to terminal as is.
+func (t *Terminal) Write(s string) error {
+	_, err := t.f.WriteString(s)
+	return err
+}
+
+// Size returns the width and height of terminal, 80x24 if unknown.
+func (t *Terminal) Size() (int, int) {
+	w, h, err := term.GetSize(int(t.f.Fd()))
+	if err != nil || w <= 0 || h <= 0 {
+		return 80, 24
+	}
+	return w, h
+}
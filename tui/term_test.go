@@ -0,0 +1,66 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		n     string
+		input string
+		want  Key
+	}{
+		{n: "empty", input: "", want: Key{Code: KeyUnknown}},
+		{n: "rune", input: "a", want: Key{Code: KeyRune, Rune: 'a'}},
+		{n: "utf8", input: "中", want: Key{Code: KeyRune, Rune: '中'}},
+		{n: "up", input: "\x1b[A", want: Key{Code: KeyUp}},
+		{n: "down app mode", input: "\x1bOB", want: Key{Code: KeyDown}},
+		{n: "page down", input: "\x1b[6~", want: Key{Code: KeyPageDown}},
+		{n: "esc", input: "\x1b", want: Key{Code: KeyEsc}},
+		{n: "unknown escape", input: "\x1b[99~", want: Key{Code: KeyUnknown}},
+		{n: "enter", input: "\r", want: Key{Code: KeyEnter}},
+		{n: "backspace", input: "\x7f", want: Key{Code: KeyBackspace}},
+		{n: "ctrl-c", input: "\x03", want: Key{Code: KeyCtrlC}},
+		{n: "ctrl-r", input: "\x12", want: Key{Code: KeyCtrlR}},
+		{n: "other control", input: "\x01", want: Key{Code: KeyUnknown}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			got := parseKey([]byte(tc.input))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	want := []Key{
+		{Code: KeyRune, Rune: 'a'},
+		{Code: KeyRune, Rune: '中'},
+		{Code: KeyEnter},
+	}
+	if diff := cmp.Diff(want, parseKeys([]byte("a中\r"))); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+	if diff := cmp.Diff([]Key{{Code: KeyUp}}, parseKeys([]byte("\x1b[A"))); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+}
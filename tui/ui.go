@@ -0,0 +1,433 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/chaopeng/to/bookmark"
+)
+
+// More ANSI escape sequences for the full-screen UI.
+const (
+	altScreen   = "\x1b[?1049h"
+	mainScreen  = "\x1b[?1049l"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+	home        = "\x1b[H"
+	clearLine   = "\x1b[K"
+	dim         = "\x1b[2m"
+	helpMessage = "enter jump  ^r rename  ^x delete  ^t tag  ^e note  esc quit"
+)
+
+// minPreviewWidth is the min terminal width to show the preview pane.
+const minPreviewWidth = 60
+
+// Backend loads and changes bookmarks for Browse, changes are saved when the
+// method returns.
+type Backend interface {
+	List() ([]bookmark.Bookmark, error)
+	Rename(old, new string) error
+	Delete(name string) error
+	// Tag applies changes like "+foo" or "foo" to add and "-bar" to remove.
+	Tag(name string, changes []string) error
+	SetNote(name, note string) error
+}
+
+// inputKind is what the text input of bottom line is for.
+type inputKind int
+
+const (
+	inputNone inputKind = iota
+	inputRename
+	inputDelete
+	inputTag
+	inputNote
+)
+
+// browser is the state of the full-screen UI.
+type browser struct {
+	backend Backend
+	shorten func(path string) string
+	preview func(path string) []string
+	width   int
+	height  int
+
+	all     []bookmark.Bookmark
+	matched []bookmark.Bookmark
+	query   string
+	cursor  int
+	offset  int
+
+	input  inputKind
+	text   string
+	status string
+}
+
+func newBrowser(backend Backend, shorten func(string) string, width, height int) (*browser, error) {
+	b := &browser{
+		backend: backend,
+		shorten: shorten,
+		preview: listDir,
+		width:   width,
+		height:  height,
+	}
+	if err := b.reload(""); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// listHeight is the number of rows for bookmarks, the first line is the query
+// and the last line is the status.
+func (b *browser) listHeight() int {
+	return max(b.height-2, 1)
+}
+
+// reload lists bookmarks from backend and keeps the cursor on name if still
+// matched.
+func (b *browser) reload(name string) error {
+	all, err := b.backend.List()
+	if err != nil {
+		return err
+	}
+	b.all = all
+	b.filter(name)
+	return nil
+}
+
+// filter updates matched with query, every word of query must be in name,
+// path, note or one of the tags, case insensitive.
+func (b *browser) filter(name string) {
+	words := strings.Fields(strings.ToLower(b.query))
+	b.matched = []bookmark.Bookmark{}
+	for _, bm := range b.all {
+		fields := append([]string{bm.Name, bm.Path, bm.Note}, bm.Tags...)
+		text := strings.ToLower(strings.Join(fields, "\n"))
+		ok := true
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			b.matched = append(b.matched, bm)
+		}
+	}
+
+	b.cursor = slices.IndexFunc(b.matched, func(bm bookmark.Bookmark) bool {
+		return bm.Name == name
+	})
+	b.offset = 0
+	b.move(0)
+}
+
+// move moves the cursor by delta and scrolls to keep it visible.
+func (b *browser) move(delta int) {
+	h := b.listHeight()
+	b.cursor = max(0, min(len(b.matched)-1, b.cursor+delta))
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+h {
+		b.offset = b.cursor - h + 1
+	}
+}
+
+// selected returns the bookmark under cursor, nil if nothing matched.
+func (b *browser) selected() *bookmark.Bookmark {
+	if len(b.matched) == 0 {
+		return nil
+	}
+	return &b.matched[b.cursor]
+}
+
+// handle updates the state with key, returns true if done. The returned
+// bookmark is the one to jump to, nil if quit.
+func (b *browser) handle(k Key) (jump *bookmark.Bookmark, done bool) {
+	if b.input != inputNone {
+		b.handleInput(k)
+		return nil, false
+	}
+
+	b.status = ""
+	switch k.Code {
+	case KeyUp, KeyCtrlP:
+		b.move(-1)
+	case KeyDown, KeyCtrlN, KeyTab:
+		b.move(1)
+	case KeyPageUp:
+		b.move(-b.listHeight())
+	case KeyPageDown:
+		b.move(b.listHeight())
+	case KeyEnter:
+		if sel := b.selected(); sel != nil {
+			return sel, true
+		}
+	case KeyEsc, KeyCtrlC, KeyCtrlD:
+		return nil, true
+	case KeyRune:
+		b.query += string(k.Rune)
+		b.filter(b.selectedName())
+	case KeyBackspace:
+		if r := []rune(b.query); len(r) > 0 {
+			b.query = string(r[:len(r)-1])
+			b.filter(b.selectedName())
+		}
+	case KeyCtrlU:
+		b.query = ""
+		b.filter(b.selectedName())
+	case KeyCtrlR:
+		b.startInput(inputRename)
+	case KeyCtrlX:
+		b.startInput(inputDelete)
+	case KeyCtrlT:
+		b.startInput(inputTag)
+	case KeyCtrlE:
+		b.startInput(inputNote)
+	}
+	return nil, false
+}
+
+func (b *browser) selectedName() string {
+	if sel := b.selected(); sel != nil {
+		return sel.Name
+	}
+	return ""
+}
+
+// startInput starts the text input for the selected bookmark, the text is
+// prefilled with the current value.
+func (b *browser) startInput(kind inputKind) {
+	sel := b.selected()
+	if sel == nil {
+		return
+	}
+	b.input = kind
+	switch kind {
+	case inputRename:
+		b.text = sel.Name
+	case inputNote:
+		b.text = sel.Note
+	default:
+		b.text = ""
+	}
+}
+
+func (b *browser) handleInput(k Key) {
+	if b.input == inputDelete {
+		if k.Code == KeyRune && (k.Rune == 'y' || k.Rune == 'Y') {
+			b.submit()
+		}
+		b.input = inputNone
+		return
+	}
+
+	switch k.Code {
+	case KeyEnter:
+		b.submit()
+		b.input = inputNone
+	case KeyEsc, KeyCtrlC:
+		b.input = inputNone
+	case KeyRune:
+		b.text += string(k.Rune)
+	case KeyBackspace:
+		if r := []rune(b.text); len(r) > 0 {
+			b.text = string(r[:len(r)-1])
+		}
+	case KeyCtrlU:
+		b.text = ""
+	}
+}
+
+// submit applies the input to the selected bookmark and reloads.
+func (b *browser) submit() {
+	name := b.selected().Name
+	keep := name
+	var err error
+	switch b.input {
+	case inputRename:
+		keep = strings.TrimSpace(b.text)
+		err = b.backend.Rename(name, keep)
+		b.status = fmt.Sprintf("renamed %v to %v", name, keep)
+	case inputDelete:
+		err = b.backend.Delete(name)
+		b.status = fmt.Sprintf("deleted %v", name)
+	case inputTag:
+		err = b.backend.Tag(name, strings.Fields(b.text))
+		b.status = fmt.Sprintf("tagged %v", name)
+	case inputNote:
+		err = b.backend.SetNote(name, strings.TrimSpace(b.text))
+		b.status = fmt.Sprintf("set note of %v", name)
+	}
+	if err != nil {
+		b.status = err.Error()
+		return
+	}
+
+	// Keep the cursor around the deleted one.
+	cursor := b.cursor
+	if err := b.reload(keep); err != nil {
+		b.status = err.Error()
+		return
+	}
+	if b.input == inputDelete {
+		b.cursor = cursor
+		b.move(0)
+	}
+}
+
+// prompt returns the bottom line.
+func (b *browser) prompt() string {
+	sel := b.selected()
+	switch b.input {
+	case inputRename:
+		return fmt.Sprintf("rename %v to: %v", sel.Name, b.text)
+	case inputDelete:
+		return fmt.Sprintf("delete %v? (y/N)", sel.Name)
+	case inputTag:
+		return fmt.Sprintf("tag %v (+a -b): %v", sel.Name, b.text)
+	case inputNote:
+		return fmt.Sprintf("note of %v: %v", sel.Name, b.text)
+	}
+	if b.status != "" {
+		return b.status
+	}
+	return helpMessage
+}
+
+// render returns the lines to draw, the query, the list with the preview of
+// selected dir on the right if the terminal is wide enough, and the bottom
+// line.
+func (b *browser) render() []string {
+	lines := []string{fmt.Sprintf("%v> %v%v %v%v/%v%v",
+		bold, reset, b.query, dim, len(b.matched), len(b.all), reset)}
+
+	listWidth := b.width
+	var preview []string
+	if b.width >= minPreviewWidth {
+		listWidth = b.width / 2
+		if sel := b.selected(); sel != nil {
+			preview = append([]string{bold + truncate(b.shorten(sel.Path), b.width-listWidth-3) + reset},
+				b.preview(bookmark.TryExpandPath(sel.Path))...)
+		}
+	}
+
+	for row := 0; row < b.listHeight(); row++ {
+		line := ""
+		if i := b.offset + row; i < len(b.matched) {
+			line = b.item(&b.matched[i])
+		}
+		// Pad the list to align the preview.
+		if b.width >= minPreviewWidth {
+			line = pad(line, listWidth)
+		} else {
+			line = truncate(line, listWidth)
+		}
+		if b.offset+row == b.cursor && len(b.matched) > 0 {
+			line = reverse + line + reset
+		}
+		if b.width >= minPreviewWidth {
+			line += " │ "
+			if row < len(preview) {
+				line += truncate(preview[row], b.width-listWidth-3)
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	bottom := truncate(b.prompt(), b.width)
+	if b.input == inputNone && b.status == "" {
+		bottom = dim + bottom + reset
+	}
+	return append(lines, bottom)
+}
+
+// item formats bookmark as "name: path [tags] # note".
+func (b *browser) item(bm *bookmark.Bookmark) string {
+	sb := strings.Builder{}
+	sb.WriteString(bm.Name)
+	sb.WriteString(": ")
+	sb.WriteString(b.shorten(bm.Path))
+	if len(bm.Tags) > 0 {
+		sb.WriteString(" [" + strings.Join(bm.Tags, ", ") + "]")
+	}
+	if bm.Note != "" {
+		sb.WriteString(" # " + bm.Note)
+	}
+	return sb.String()
+}
+
+// pad truncates or pads s with spaces to width columns.
+func pad(s string, width int) string {
+	s = truncate(s, width)
+	if n := len([]rune(s)); n < width {
+		s += strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// listDir lists the entries of dir, sub dirs end with "/".
+func listDir(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []string{fmt.Sprintf("(%v)", err)}
+	}
+	res := []string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			res = append(res, e.Name()+"/")
+		} else {
+			res = append(res, e.Name())
+		}
+	}
+	return res
+}
+
+// Browse shows the full-screen UI for bookmarks, returns the bookmark to jump
+// to, or nil if user quits. shorten formats paths for display.
+func Browse(backend Backend, shorten func(path string) string) (*bookmark.Bookmark, error) {
+	t, err := OpenTerminal()
+	if err != nil {
+		return nil, err
+	}
+	defer t.Close()
+
+	w, h := t.Size()
+	b, err := newBrowser(backend, shorten, w, h)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Write(altScreen + hideCursor)
+	defer t.Write(showCursor + mainScreen)
+	for {
+		b.width, b.height = t.Size()
+		b.move(0)
+		t.Write(home + strings.Join(b.render(), clearLine+"\r\n") + clearLine + clearDown)
+
+		k, err := t.ReadKey()
+		if err != nil {
+			return nil, err
+		}
+		if jump, done := b.handle(k); done {
+			return jump, nil
+		}
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2023 chaopeng@chaopeng.me
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chaopeng/to/bookmark"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var ignoreTimes = cmpopts.IgnoreFields(bookmark.Bookmark{}, "Created", "Updated")
+
+// fakeBackend keeps bookmarks in memory.
+type fakeBackend struct {
+	b *bookmark.Bookmarks
+}
+
+func (f *fakeBackend) List() ([]bookmark.Bookmark, error) {
+	return f.b.ListWithFilters(nil), nil
+}
+
+func (f *fakeBackend) Rename(old, new string) error {
+	return f.b.Rename(old, new)
+}
+
+func (f *fakeBackend) Delete(name string) error {
+	return f.b.Delete(name)
+}
+
+func (f *fakeBackend) Tag(name string, changes []string) error {
+	add := []string{}
+	remove := []string{}
+	for _, c := range changes {
+		if strings.HasPrefix(c, "-") {
+			remove = append(remove, c[1:])
+		} else {
+			add = append(add, strings.TrimPrefix(c, "+"))
+		}
+	}
+	_, err := f.b.Tag(name, add, remove)
+	return err
+}
+
+func (f *fakeBackend) SetNote(name, note string) error {
+	return f.b.SetNote(name, note)
+}
+
+func newTestBrowser(t *testing.T) (*browser, *fakeBackend) {
+	t.Helper()
+	b := bookmark.NewBookMarkForTesting()
+	b.Add("api", "/src/api")
+	b.Add("docs", "/src/docs")
+	b.Add("web", "/src/web")
+	b.Tag("api", []string{"prod"}, nil)
+	b.SetNote("web", "frontend")
+
+	backend := &fakeBackend{b}
+	br, err := newBrowser(backend, func(p string) string { return p }, 40, 10)
+	if err != nil {
+		t.Fatalf("newBrowser failed: %v", err)
+	}
+	br.preview = func(p string) []string { return []string{"a/", "b"} }
+	return br, backend
+}
+
+// typeKeys returns the key presses of typing s.
+func typeKeys(s string) []Key {
+	res := []Key{}
+	for _, r := range s {
+		res = append(res, Key{Code: KeyRune, Rune: r})
+	}
+	return res
+}
+
+func names(l []bookmark.Bookmark) []string {
+	res := []string{}
+	for _, bm := range l {
+		res = append(res, bm.Name)
+	}
+	return res
+}
+
+func TestBrowserFilter(t *testing.T) {
+	tests := []struct {
+		n     string
+		keys  []Key
+		want  []string
+		wantQ string
+	}{
+		{n: "all", want: []string{"api", "docs", "web"}},
+		{n: "name", keys: typeKeys("do"), want: []string{"docs"}, wantQ: "do"},
+		{n: "tag", keys: typeKeys("PROD"), want: []string{"api"}, wantQ: "PROD"},
+		{n: "note", keys: typeKeys("front"), want: []string{"web"}, wantQ: "front"},
+		{n: "all words", keys: typeKeys("src w"), want: []string{"web"}, wantQ: "src w"},
+		{n: "none", keys: typeKeys("zz"), want: []string{}, wantQ: "zz"},
+		{n: "backspace", keys: append(typeKeys("zz"), Key{Code: KeyBackspace}, Key{Code: KeyBackspace}),
+			want: []string{"api", "docs", "web"}},
+		{n: "clear", keys: append(typeKeys("do"), Key{Code: KeyCtrlU}), want: []string{"api", "docs", "web"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			br, _ := newTestBrowser(t)
+			for _, k := range tc.keys {
+				br.handle(k)
+			}
+			if diff := cmp.Diff(tc.want, names(br.matched)); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+			if br.query != tc.wantQ {
+				t.Errorf("want query %q, got %q", tc.wantQ, br.query)
+			}
+		})
+	}
+}
+
+func TestBrowserJump(t *testing.T) {
+	br, _ := newTestBrowser(t)
+	br.handle(Key{Code: KeyDown})
+	jump, done := br.handle(Key{Code: KeyEnter})
+	if !done || jump == nil || jump.Name != "docs" {
+		t.Errorf("want jump to docs, got %v %v", jump, done)
+	}
+
+	br, _ = newTestBrowser(t)
+	jump, done = br.handle(Key{Code: KeyEsc})
+	if !done || jump != nil {
+		t.Errorf("want quit, got %v %v", jump, done)
+	}
+
+	br, _ = newTestBrowser(t)
+	for _, k := range typeKeys("zz") {
+		br.handle(k)
+	}
+	if jump, done = br.handle(Key{Code: KeyEnter}); done {
+		t.Errorf("want no jump if nothing matched, got %v", jump)
+	}
+}
+
+func TestBrowserActions(t *testing.T) {
+	ctrl := func(c KeyCode) Key { return Key{Code: c} }
+	enter := Key{Code: KeyEnter}
+
+	tests := []struct {
+		n          string
+		keys       []Key
+		want       []bookmark.Bookmark
+		wantCursor string
+		wantStatus string
+	}{
+		{
+			n:    "rename",
+			keys: append(append([]Key{ctrl(KeyCtrlR), ctrl(KeyCtrlU)}, typeKeys("zapi")...), enter),
+			want: []bookmark.Bookmark{
+				{Name: "docs", Path: "/src/docs"},
+				{Name: "web", Path: "/src/web", Note: "frontend"},
+				{Name: "zapi", Path: "/src/api", Tags: []string{"prod"}},
+			},
+			wantCursor: "zapi",
+			wantStatus: "renamed api to zapi",
+		},
+		{
+			n:    "rename conflict",
+			keys: append(append([]Key{ctrl(KeyCtrlR), ctrl(KeyCtrlU)}, typeKeys("web")...), enter),
+			want: []bookmark.Bookmark{
+				{Name: "api", Path: "/src/api", Tags: []string{"prod"}},
+				{Name: "docs", Path: "/src/docs"},
+				{Name: "web", Path: "/src/web", Note: "frontend"},
+			},
+			wantCursor: "api",
+			wantStatus: "bookmark web already exists",
+		},
+		{
+			n:    "delete",
+			keys: []Key{ctrl(KeyDown), ctrl(KeyCtrlX), {Code: KeyRune, Rune: 'y'}},
+			want: []bookmark.Bookmark{
+				{Name: "api", Path: "/src/api", Tags: []string{"prod"}},
+				{Name: "web", Path: "/src/web", Note: "frontend"},
+			},
+			wantCursor: "web",
+			wantStatus: "deleted docs",
+		},
+		{
+			n:    "delete canceled",
+			keys: []Key{ctrl(KeyCtrlX), {Code: KeyRune, Rune: 'n'}},
+			want: []bookmark.Bookmark{
+				{Name: "api", Path: "/src/api", Tags: []string{"prod"}},
+				{Name: "docs", Path: "/src/docs"},
+				{Name: "web", Path: "/src/web", Note: "frontend"},
+			},
+			wantCursor: "api",
+		},
+		{
+			n:    "tag",
+			keys: append(append([]Key{ctrl(KeyCtrlT)}, typeKeys("-prod +go")...), enter),
+			want: []bookmark.Bookmark{
+				{Name: "api", Path: "/src/api", Tags: []string{"go"}},
+				{Name: "docs", Path: "/src/docs"},
+				{Name: "web", Path: "/src/web", Note: "frontend"},
+			},
+			wantCursor: "api",
+			wantStatus: "tagged api",
+		},
+		{
+			n: "note",
+			keys: append(append([]Key{ctrl(KeyDown), ctrl(KeyDown), ctrl(KeyCtrlE), ctrl(KeyCtrlU)},
+				typeKeys("ui app")...), enter),
+			want: []bookmark.Bookmark{
+				{Name: "api", Path: "/src/api", Tags: []string{"prod"}},
+				{Name: "docs", Path: "/src/docs"},
+				{Name: "web", Path: "/src/web", Note: "ui app"},
+			},
+			wantCursor: "web",
+			wantStatus: "set note of web",
+		},
+		{
+			n:    "note canceled",
+			keys: append(append([]Key{ctrl(KeyCtrlE)}, typeKeys("x")...), ctrl(KeyEsc)),
+			want: []bookmark.Bookmark{
+				{Name: "api", Path: "/src/api", Tags: []string{"prod"}},
+				{Name: "docs", Path: "/src/docs"},
+				{Name: "web", Path: "/src/web", Note: "frontend"},
+			},
+			wantCursor: "api",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.n, func(t *testing.T) {
+			br, backend := newTestBrowser(t)
+			for _, k := range tc.keys {
+				if _, done := br.handle(k); done {
+					t.Fatalf("unexpected done on key %v", k)
+				}
+			}
+			got, _ := backend.List()
+			if diff := cmp.Diff(tc.want, got, ignoreTimes); diff != "" {
+				t.Errorf("-want +got: %v", diff)
+			}
+			if br.selectedName() != tc.wantCursor {
+				t.Errorf("want cursor on %v, got %v", tc.wantCursor, br.selectedName())
+			}
+			if br.status != tc.wantStatus {
+				t.Errorf("want status %q, got %q", tc.wantStatus, br.status)
+			}
+			if br.input != inputNone {
+				t.Errorf("want input done, got %v", br.input)
+			}
+		})
+	}
+}
+
+func TestBrowserRender(t *testing.T) {
+	br, _ := newTestBrowser(t)
+	br.height = 5
+	br.handle(Key{Code: KeyDown})
+
+	want := []string{
+		bold + "> " + reset + " " + dim + "3/3" + reset,
+		"api: /src/api [prod]",
+		reverse + "docs: /src/docs" + reset,
+		"web: /src/web # frontend",
+		dim + truncate(helpMessage, 40) + reset,
+	}
+	if diff := cmp.Diff(want, br.render()); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	// Wide terminal shows the preview of selected dir on the right.
+	br.width = 60
+	want = []string{
+		bold + "> " + reset + " " + dim + "3/3" + reset,
+		pad("api: /src/api [prod]", 30) + " │ " + bold + "/src/docs" + reset,
+		reverse + pad("docs: /src/docs", 30) + reset + " │ a/",
+		pad("web: /src/web # frontend", 30) + " │ b",
+		dim + helpMessage + reset,
+	}
+	if diff := cmp.Diff(want, br.render()); diff != "" {
+		t.Errorf("-want +got: %v", diff)
+	}
+
+	br.handle(Key{Code: KeyCtrlR})
+	if got := br.render()[4]; got != "rename docs to: docs" {
+		t.Errorf("want rename prompt, got %q", got)
+	}
+}